@@ -0,0 +1,19 @@
+package syncx
+
+import "time"
+
+// Clock tells the time. Primitives that schedule work accept one so tests can
+// control the passage of time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel that receives the current time once d has
+	// elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the [Clock] backed by package time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
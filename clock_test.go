@@ -0,0 +1,80 @@
+package syncx
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a [Clock] whose time only moves when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing any channels that became due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiters := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiters = append(waiters, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiters
+}
+
+// BlockUntil waits until n channels returned by After are waiting to fire, so
+// that advancing the clock can't race with a goroutine about to call After.
+func (c *fakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		waiting := len(c.waiters)
+		c.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		time.Sleep(time.Microsecond)
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	var c Clock = systemClock{}
+	start := c.Now()
+	<-c.After(time.Millisecond)
+	if c.Now().Sub(start) < time.Millisecond {
+		t.Fatal("expected at least a millisecond to pass")
+	}
+}
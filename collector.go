@@ -0,0 +1,168 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// Collector runs tasks that each produce a result, and collects the results
+// both in the order the tasks were started and in the order they finished. The
+// zero value runs tasks with [context.Background] and is safe to use. A
+// Collector must not be copied after first use.
+//
+//	c := syncx.NewCollector[Reply](ctx)
+//	for _, replica := range replicas {
+//	    c.Go(func(ctx context.Context) (Reply, error) {
+//	        return replica.Read(ctx, key)
+//	    })
+//	}
+//	select {
+//	case <-c.AwaitN(quorum):
+//	case <-c.Await():
+//	    // too many replicas failed to reach a quorum.
+//	}
+type Collector[T any] struct {
+	ctx context.Context
+	wg  WaitGroup
+	mu  Mutex
+	// results are in the order tasks were started.
+	results []result[T]
+	// order holds indexes into results in the order tasks finished.
+	order     []int
+	pending   int
+	succeeded int
+	// changed is closed and replaced whenever a task finishes. It is nil when
+	// nobody is waiting.
+	changed chan struct{}
+	// quorums holds channels to close once enough tasks have succeeded.
+	quorums []thresholdWaiter
+}
+
+type result[T any] struct {
+	v   T
+	err error
+}
+
+// NewCollector returns a Collector that passes ctx to its tasks.
+func NewCollector[T any](ctx context.Context) *Collector[T] {
+	return &Collector[T]{ctx: ctx}
+}
+
+// Go runs f in a new goroutine and collects its result.
+func (c *Collector[T]) Go(f func(ctx context.Context) (T, error)) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.mu.Lock()
+	i := len(c.results)
+	c.results = append(c.results, result[T]{})
+	c.pending++
+	c.mu.Unlock()
+	c.wg.Go(func() {
+		v, err := f(ctx)
+		c.finish(i, v, err)
+	})
+}
+
+// finish records the result of task i.
+func (c *Collector[T]) finish(i int, v T, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[i] = result[T]{v: v, err: err}
+	c.order = append(c.order, i)
+	c.pending--
+	if err == nil {
+		c.succeeded++
+		waiting := c.quorums[:0]
+		for _, w := range c.quorums {
+			if c.succeeded >= w.n {
+				close(w.ch)
+				continue
+			}
+			waiting = append(waiting, w)
+		}
+		clear(c.quorums[len(waiting):])
+		c.quorums = waiting
+	}
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+// All waits for every task to finish and returns their values in the order
+// the tasks were started, along with their errors joined in the same order.
+// Failed tasks leave a zero value in their place. Returns ctx's error if it is
+// done first.
+func (c *Collector[T]) All(ctx context.Context) ([]T, error) {
+	if err := c.wg.WaitContext(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vs := make([]T, len(c.results))
+	errs := make([]error, len(c.results))
+	for i, r := range c.results {
+		vs[i], errs[i] = r.v, r.err
+	}
+	return vs, errors.Join(errs...)
+}
+
+// Completed returns an iterator over task results in the order the tasks
+// finished. It blocks waiting for outstanding tasks, including ones started
+// while iterating, and stops once none are left. If ctx is done while
+// waiting, it yields a zero value and ctx's error and stops.
+func (c *Collector[T]) Completed(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for i := 0; ; i++ {
+			c.mu.Lock()
+			for i >= len(c.order) {
+				if c.pending == 0 {
+					c.mu.Unlock()
+					return
+				}
+				if c.changed == nil {
+					c.changed = make(chan struct{})
+				}
+				ch := c.changed
+				c.mu.Unlock()
+				select {
+				case <-ch:
+				case <-ctx.Done():
+					var zero T
+					yield(zero, ctx.Err())
+					return
+				}
+				c.mu.Lock()
+			}
+			r := c.results[c.order[i]]
+			c.mu.Unlock()
+			if !yield(r.v, r.err) {
+				return
+			}
+		}
+	}
+}
+
+// AwaitN returns a channel that will be closed once n tasks have succeeded. If
+// they already have, the channel is closed. The channel never closes if too
+// many tasks fail, so callers should also select on [Collector.Await].
+func (c *Collector[T]) AwaitN(n int) <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan struct{})
+	if c.succeeded >= n {
+		close(ch)
+		return ch
+	}
+	c.quorums = append(c.quorums, thresholdWaiter{n: n, ch: ch})
+	return ch
+}
+
+// Await returns a channel that will be closed once every task has finished.
+// Short for calling [WaitGroup.Await].
+func (c *Collector[T]) Await() <-chan struct{} {
+	return c.wg.Await()
+}
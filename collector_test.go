@@ -0,0 +1,150 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCollectorAll(t *testing.T) {
+	t.Run("returns values in start order", func(t *testing.T) {
+		var c Collector[int]
+		for i := range 5 {
+			c.Go(func(context.Context) (int, error) {
+				time.Sleep(time.Duration(5-i) * time.Millisecond)
+				return i, nil
+			})
+		}
+		vs, err := c.All(t.Context())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !slices.Equal(vs, []int{0, 1, 2, 3, 4}) {
+			t.Fatalf("expected [0 1 2 3 4], got %v", vs)
+		}
+	})
+	t.Run("joins errors", func(t *testing.T) {
+		var c Collector[int]
+		errA, errB := errors.New("a"), errors.New("b")
+		c.Go(func(context.Context) (int, error) { return 0, errA })
+		c.Go(func(context.Context) (int, error) { return 1, nil })
+		c.Go(func(context.Context) (int, error) { return 0, errB })
+		vs, err := c.All(t.Context())
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Fatalf("expected joined errors, got %v", err)
+		}
+		if vs[1] != 1 {
+			t.Fatalf("expected successful value to be kept, got %v", vs)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var c Collector[int]
+		release := make(chan struct{})
+		defer close(release)
+		c.Go(func(context.Context) (int, error) {
+			<-release
+			return 0, nil
+		})
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := c.All(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+	t.Run("passes context to tasks", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(t.Context(), key{}, "v")
+		c := NewCollector[any](ctx)
+		c.Go(func(ctx context.Context) (any, error) {
+			return ctx.Value(key{}), nil
+		})
+		vs, _ := c.All(t.Context())
+		if vs[0] != "v" {
+			t.Fatalf("expected task to receive the collector context, got %v", vs[0])
+		}
+	})
+}
+
+func TestCollectorCompleted(t *testing.T) {
+	var c Collector[int]
+	steps := make([]chan struct{}, 3)
+	for i := range steps {
+		steps[i] = make(chan struct{})
+		c.Go(func(context.Context) (int, error) {
+			<-steps[i]
+			return i, nil
+		})
+	}
+	// finish tasks in reverse order, one at a time.
+	close(steps[len(steps)-1])
+	var got []int
+	for v, err := range c.Completed(t.Context()) {
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		got = append(got, v)
+		if v > 0 {
+			close(steps[v-1])
+		}
+	}
+	if !slices.Equal(got, []int{2, 1, 0}) {
+		t.Fatalf("expected completion order [2 1 0], got %v", got)
+	}
+	t.Run("break stops iteration", func(t *testing.T) {
+		for range c.Completed(t.Context()) {
+			break
+		}
+	})
+	t.Run("returns immediately without tasks", func(t *testing.T) {
+		var c Collector[int]
+		for range c.Completed(t.Context()) {
+			t.Fatal("expected no results")
+		}
+	})
+	t.Run("yields context error", func(t *testing.T) {
+		var c Collector[int]
+		release := make(chan struct{})
+		defer close(release)
+		c.Go(func(context.Context) (int, error) {
+			<-release
+			return 0, nil
+		})
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		var errs []error
+		for _, err := range c.Completed(ctx) {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || errs[0] != context.DeadlineExceeded {
+			t.Fatalf("expected a single deadline exceeded, got %v", errs)
+		}
+	})
+}
+
+func TestCollectorAwaitN(t *testing.T) {
+	var c Collector[int]
+	release := make(chan struct{})
+	c.Go(func(context.Context) (int, error) { return 0, errors.New("failed") })
+	c.Go(func(context.Context) (int, error) { return 1, nil })
+	c.Go(func(context.Context) (int, error) { return 2, nil })
+	c.Go(func(context.Context) (int, error) {
+		<-release
+		return 3, nil
+	})
+	<-c.AwaitN(2)
+	select {
+	case <-c.AwaitN(3):
+		t.Fatal("expected open channel until a third task succeeds")
+	default:
+	}
+	select {
+	case <-c.AwaitN(0):
+	default:
+		t.Fatal("expected closed channel for a quorum of zero")
+	}
+	close(release)
+	<-c.AwaitN(3)
+	<-c.Await()
+}
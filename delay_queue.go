@@ -0,0 +1,216 @@
+package syncx
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DelayQueue is a queue of items that can only be popped once their deadline
+// has passed, earliest deadline first. It replaces a timer per item with a
+// single heap, so it scales to large numbers of scheduled items. Popping is
+// cancellable and can be used in select statements via [DelayQueue.Out].
+// Scheduled items can be cancelled via the handle returned when scheduling
+// them. The zero value is an empty queue that is safe to use. A DelayQueue
+// must not be copied after first use.
+//
+// Closing a queue rejects new items while the remaining ones are drained as
+// they become due.
+type DelayQueue[T any] struct {
+	// Clock optionally overrides the system clock. Must be set before first
+	// use.
+	Clock Clock
+
+	mu     Mutex
+	items  delayHeap[T]
+	closed bool
+	// changed is signalled whenever items or closed change.
+	changed notifier
+	outOnce sync.Once
+	out     chan T
+}
+
+// DelayItem is a handle to an item scheduled on a [DelayQueue].
+type DelayItem[T any] struct {
+	v  T
+	at time.Time
+	// index is the position in the heap, or itemPopped.
+	index int
+}
+
+// Schedule adds v to dq, to become available once the clock reaches at.
+// Panics if dq is closed.
+func (dq *DelayQueue[T]) Schedule(v T, at time.Time) *DelayItem[T] {
+	it, err := dq.ScheduleContext(context.Background(), v, at)
+	if err != nil {
+		panic("schedule on closed queue")
+	}
+	return it
+}
+
+// ScheduleContext adds v to dq, to become available once the clock reaches at.
+// Returns [ErrClosed] if dq is closed, or ctx's error if it is done before dq
+// could be locked.
+func (dq *DelayQueue[T]) ScheduleContext(ctx context.Context, v T, at time.Time) (*DelayItem[T], error) {
+	if err := dq.mu.LockContext(ctx); err != nil {
+		return nil, err
+	}
+	defer dq.mu.Unlock()
+	if dq.closed {
+		return nil, ErrClosed
+	}
+	it := &DelayItem[T]{v: v, at: at}
+	heap.Push(&dq.items, it)
+	// waiters only need to reschedule their timer for a new earliest item.
+	if it.index == 0 {
+		dq.changed.signal()
+	}
+	return it, nil
+}
+
+// ScheduleAfter adds v to dq, to become available once d has elapsed. Short
+// for calling [DelayQueue.Schedule].
+func (dq *DelayQueue[T]) ScheduleAfter(v T, d time.Duration) *DelayItem[T] {
+	return dq.Schedule(v, dq.clock().Now().Add(d))
+}
+
+// Cancel removes it from dq. Reports false if it was already popped or
+// cancelled.
+func (dq *DelayQueue[T]) Cancel(it *DelayItem[T]) bool {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	if it.index == itemPopped {
+		return false
+	}
+	head := it.index == 0
+	heap.Remove(&dq.items, it.index)
+	it.index = itemPopped
+	if head {
+		dq.changed.signal()
+	}
+	return true
+}
+
+// PopContext removes and returns the item with the earliest deadline, blocking
+// until it is due. Returns [ErrClosed] once dq is closed and drained, or ctx's
+// error if it is done first.
+func (dq *DelayQueue[T]) PopContext(ctx context.Context) (T, error) {
+	var zero T
+	clock := dq.clock()
+	for {
+		if err := dq.mu.LockContext(ctx); err != nil {
+			return zero, err
+		}
+		if it, ok := dq.pop(clock.Now()); ok {
+			dq.mu.Unlock()
+			return it.v, nil
+		}
+		if dq.closed && len(dq.items) == 0 {
+			dq.mu.Unlock()
+			return zero, ErrClosed
+		}
+		var due <-chan time.Time
+		if len(dq.items) > 0 {
+			due = clock.After(dq.items[0].at.Sub(clock.Now()))
+		}
+		ch := dq.changed.wait()
+		dq.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-ch:
+		case <-due:
+		}
+	}
+}
+
+// TryPop removes and returns the item with the earliest deadline and reports
+// whether it was due.
+func (dq *DelayQueue[T]) TryPop() (T, bool) {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	it, ok := dq.pop(dq.clock().Now())
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return it.v, true
+}
+
+// Out returns a channel that receives items as they become due. It is closed
+// once dq is closed and drained. Do not close it.
+//
+// The first call starts a goroutine that forwards items, so one popped item
+// may be held waiting for a receiver. It can no longer be cancelled and will
+// not be counted by [DelayQueue.Len].
+func (dq *DelayQueue[T]) Out() <-chan T {
+	dq.outOnce.Do(func() {
+		dq.out = make(chan T)
+		go forward(dq.out, dq.PopContext)
+	})
+	return dq.out
+}
+
+// Len returns the number of scheduled items, due or not.
+func (dq *DelayQueue[T]) Len() int {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	return len(dq.items)
+}
+
+// Close stops dq from accepting new items. Items already scheduled can still be
+// popped once due. Calling Close more than once is a no-op.
+func (dq *DelayQueue[T]) Close() {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	dq.closed = true
+	dq.changed.signal()
+}
+
+// clock gets the configured clock or the system clock.
+func (dq *DelayQueue[T]) clock() Clock {
+	if dq.Clock == nil {
+		return systemClock{}
+	}
+	return dq.Clock
+}
+
+// pop removes the earliest item if it is due at now. Must hold mu.
+func (dq *DelayQueue[T]) pop(now time.Time) (*DelayItem[T], bool) {
+	if len(dq.items) == 0 || dq.items[0].at.After(now) {
+		return nil, false
+	}
+	it := heap.Pop(&dq.items).(*DelayItem[T])
+	it.index = itemPopped
+	dq.changed.signal()
+	return it, true
+}
+
+// delayHeap implements [heap.Interface] for [DelayItem] handles, earliest
+// deadline first.
+type delayHeap[T any] []*DelayItem[T]
+
+func (h delayHeap[T]) Len() int           { return len(h) }
+func (h delayHeap[T]) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h delayHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *delayHeap[T]) Push(x any) {
+	it := x.(*DelayItem[T])
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *delayHeap[T]) Pop() any {
+	old := *h
+	n := len(old) - 1
+	it := old[n]
+	old[n] = nil
+	*h = old[:n]
+	return it
+}
@@ -0,0 +1,174 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelayQueueTryPop(t *testing.T) {
+	clock := newFakeClock()
+	dq := DelayQueue[string]{Clock: clock}
+	dq.ScheduleAfter("b", 2*time.Second)
+	dq.ScheduleAfter("a", time.Second)
+	if _, ok := dq.TryPop(); ok {
+		t.Fatal("expected no item to be due")
+	}
+	clock.Advance(time.Second)
+	if v, ok := dq.TryPop(); !ok || v != "a" {
+		t.Fatalf("expected a, true, got %q, %t", v, ok)
+	}
+	if _, ok := dq.TryPop(); ok {
+		t.Fatal("expected no item to be due")
+	}
+	clock.Advance(time.Second)
+	if v, ok := dq.TryPop(); !ok || v != "b" {
+		t.Fatalf("expected b, true, got %q, %t", v, ok)
+	}
+	if n := dq.Len(); n != 0 {
+		t.Fatalf("expected empty queue, got %d", n)
+	}
+}
+
+func TestDelayQueuePopContext(t *testing.T) {
+	t.Run("blocks until due", func(t *testing.T) {
+		clock := newFakeClock()
+		dq := DelayQueue[int]{Clock: clock}
+		dq.ScheduleAfter(1, time.Minute)
+		got := make(chan int)
+		go func() {
+			v, _ := dq.PopContext(t.Context())
+			got <- v
+		}()
+		clock.BlockUntil(1)
+		select {
+		case <-got:
+			t.Fatal("popped item before it was due")
+		default:
+		}
+		clock.Advance(time.Minute)
+		if v := <-got; v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	})
+	t.Run("wakes for an earlier item", func(t *testing.T) {
+		clock := newFakeClock()
+		dq := DelayQueue[int]{Clock: clock}
+		dq.ScheduleAfter(2, time.Hour)
+		got := make(chan int)
+		go func() {
+			v, _ := dq.PopContext(t.Context())
+			got <- v
+		}()
+		clock.BlockUntil(1)
+		dq.ScheduleAfter(1, 0)
+		if v := <-got; v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	})
+	t.Run("does not wake for a later item", func(t *testing.T) {
+		clock := newFakeClock()
+		dq := DelayQueue[int]{Clock: clock}
+		dq.ScheduleAfter(1, time.Hour)
+		got := make(chan int)
+		go func() {
+			v, _ := dq.PopContext(t.Context())
+			got <- v
+		}()
+		clock.BlockUntil(1)
+		dq.ScheduleAfter(2, 2*time.Hour)
+		time.Sleep(5 * time.Millisecond)
+		// a woken waiter would have asked for a second timer.
+		clock.mu.Lock()
+		timers := len(clock.waiters)
+		clock.mu.Unlock()
+		if timers != 1 {
+			t.Fatalf("expected 1 timer, got %d", timers)
+		}
+		clock.Advance(time.Hour)
+		if v := <-got; v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	})
+	t.Run("uses the system clock by default", func(t *testing.T) {
+		var dq DelayQueue[int]
+		dq.ScheduleAfter(1, time.Millisecond)
+		if v, err := dq.PopContext(t.Context()); err != nil || v != 1 {
+			t.Fatalf("expected 1, nil, got %d, %v", v, err)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var dq DelayQueue[int]
+		dq.ScheduleAfter(1, time.Hour)
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := dq.PopContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestDelayQueueCancel(t *testing.T) {
+	clock := newFakeClock()
+	dq := DelayQueue[int]{Clock: clock}
+	it := dq.ScheduleAfter(1, time.Second)
+	dq.ScheduleAfter(2, time.Second)
+	if !dq.Cancel(it) {
+		t.Fatal("expected Cancel to succeed")
+	}
+	if dq.Cancel(it) {
+		t.Fatal("expected second Cancel to fail")
+	}
+	clock.Advance(time.Second)
+	if v, _ := dq.TryPop(); v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+}
+
+func TestDelayQueueClose(t *testing.T) {
+	clock := newFakeClock()
+	dq := DelayQueue[int]{Clock: clock}
+	dq.ScheduleAfter(1, time.Second)
+	dq.Close()
+	func() {
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected Schedule to panic")
+			}
+		}()
+		dq.ScheduleAfter(2, 0)
+	}()
+	if _, err := dq.ScheduleContext(t.Context(), 2, clock.Now()); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	clock.Advance(time.Second)
+	if v, err := dq.PopContext(t.Context()); err != nil || v != 1 {
+		t.Fatalf("expected 1, nil, got %d, %v", v, err)
+	}
+	if _, err := dq.PopContext(t.Context()); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestDelayQueueOut(t *testing.T) {
+	clock := newFakeClock()
+	dq := DelayQueue[int]{Clock: clock}
+	for i := range 3 {
+		dq.ScheduleAfter(i, time.Duration(i)*time.Second)
+	}
+	dq.Close()
+	out := dq.Out()
+	for i := range 3 {
+		if v := <-out; v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+		if i < 2 {
+			clock.BlockUntil(1)
+			clock.Advance(time.Second)
+		}
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("expected Out to be closed once drained")
+	}
+}
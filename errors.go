@@ -0,0 +1,14 @@
+package syncx
+
+import "errors"
+
+// ErrClosed is returned when operating on a primitive that has been closed.
+var ErrClosed = errors.New("syncx: closed")
+
+// ErrTooManyRestarts is returned when a [Supervisor] gives up because its
+// children restarted more often than allowed.
+var ErrTooManyRestarts = errors.New("syncx: too many restarts")
+
+// ErrDependencyCycle is returned when the dependencies of the services in a
+// [Lifecycle] form a cycle.
+var ErrDependencyCycle = errors.New("syncx: dependency cycle")
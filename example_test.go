@@ -145,3 +145,37 @@ func ExampleWaitGroup_WaitContext() {
 	// Output:
 	// Waiting cancelled: context canceled
 }
+
+func ExampleWithLock() {
+	var mu syncx.Mutex
+	balance := 0
+
+	// The lock is released when the closure returns, even if it panics.
+	err := syncx.WithLock(context.Background(), &mu, func() error {
+		balance += 10
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	fmt.Println("balance:", balance)
+
+	// Output:
+	// balance: 10
+}
+
+func ExampleQueue() {
+	var q syncx.Queue[string]
+	q.Push("first")
+	q.Push("second")
+
+	// Closing lets the remaining items drain before Out is closed.
+	q.Close()
+	for job := range q.Out() {
+		fmt.Println(job)
+	}
+
+	// Output:
+	// first
+	// second
+}
@@ -0,0 +1,81 @@
+package syncx
+
+import "context"
+
+// Gate pauses goroutines while it is closed. Workers call [Gate.Wait] between
+// units of work, and every waiter is released when the gate is opened.
+//
+// The zero value is an open Gate. A Gate must not be copied after first use.
+//
+//	for job := range jobs {
+//	    if err := gate.Wait(ctx); err != nil {
+//	        return err
+//	    }
+//	    process(job)
+//	}
+type Gate struct {
+	mu     Mutex
+	closed bool
+	// ready is closed while the gate is open, and replaced when it closes. It
+	// is nil until someone waits.
+	ready chan struct{}
+}
+
+// Open opens the gate, releasing every waiter. Opening an open gate has no
+// effect.
+func (g *Gate) Open() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.closed {
+		return
+	}
+	g.closed = false
+	if g.ready != nil {
+		close(g.ready)
+	}
+}
+
+// Close closes the gate, so that waiters block until it is opened. Closing a
+// closed gate has no effect.
+func (g *Gate) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return
+	}
+	g.closed = true
+	// the next waiter makes a new channel.
+	g.ready = nil
+}
+
+// IsOpen reports whether the gate is open.
+func (g *Gate) IsOpen() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.closed
+}
+
+// Wait blocks until the gate is open, or ctx is done. Returns the context's
+// error if ctx is done first.
+func (g *Gate) Wait(ctx context.Context) error {
+	select {
+	case <-g.Ready():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ready returns a channel that is closed while the gate is open. If the gate
+// is closed, the channel is closed when it is next opened.
+func (g *Gate) Ready() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.ready == nil {
+		g.ready = make(chan struct{})
+		if !g.closed {
+			close(g.ready)
+		}
+	}
+	return g.ready
+}
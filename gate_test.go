@@ -0,0 +1,101 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGate(t *testing.T) {
+	t.Run("zero value is open", func(t *testing.T) {
+		var g Gate
+		if !g.IsOpen() {
+			t.Fatal("expected gate to be open")
+		}
+		if err := g.Wait(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-g.Ready():
+		default:
+			t.Fatal("expected ready channel to be closed")
+		}
+	})
+	t.Run("close blocks waiters until open", func(t *testing.T) {
+		var g Gate
+		g.Close()
+		g.Close()
+		if g.IsOpen() {
+			t.Fatal("expected gate to be closed")
+		}
+		ready := g.Ready()
+		released := make(chan struct{})
+		go func() {
+			g.Wait(context.Background())
+			close(released)
+		}()
+		select {
+		case <-released:
+			t.Fatal("expected Wait to block while closed")
+		case <-ready:
+			t.Fatal("expected ready channel to stay open while closed")
+		case <-time.After(5 * time.Millisecond):
+		}
+		g.Open()
+		g.Open()
+		<-released
+		<-ready
+	})
+	t.Run("each close gets a new channel", func(t *testing.T) {
+		var g Gate
+		g.Close()
+		first := g.Ready()
+		g.Open()
+		g.Close()
+		second := g.Ready()
+		if first == second {
+			t.Fatal("expected a new ready channel after closing again")
+		}
+		select {
+		case <-second:
+			t.Fatal("expected ready channel to stay open while closed")
+		default:
+		}
+	})
+	t.Run("wait gives up when ctx is done", func(t *testing.T) {
+		var g Gate
+		g.Close()
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		if err := g.Wait(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+// must be tested with "-race"
+func TestGate_race(t *testing.T) {
+	var g Gate
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				g.Wait(t.Context())
+			}
+		})
+	}
+	for range 1000 {
+		g.Close()
+		g.IsOpen()
+		g.Open()
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,143 @@
+package gatomic
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// COWSlice is a copy-on-write slice. Reads load the current snapshot without
+// locking. Writes clone the snapshot while holding an internal lock and then
+// publish the clone atomically, so readers never see a partial write. It suits
+// read-mostly data such as configuration. The zero value is an empty slice that
+// is safe to use. A COWSlice must not be copied after first use.
+type COWSlice[T any] struct {
+	mu sync.Mutex // serializes writers.
+	p  atomic.Pointer[[]T]
+}
+
+// Snapshot returns the current slice. It is shared with other readers and must
+// not be modified.
+func (s *COWSlice[T]) Snapshot() []T {
+	if p := s.p.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Len returns the length of the current slice.
+func (s *COWSlice[T]) Len() int {
+	return len(s.Snapshot())
+}
+
+// All returns an iterator over the indexes and values of a consistent snapshot.
+func (s *COWSlice[T]) All() iter.Seq2[int, T] {
+	return slices.All(s.Snapshot())
+}
+
+// Values returns an iterator over the values of a consistent snapshot.
+func (s *COWSlice[T]) Values() iter.Seq[T] {
+	return slices.Values(s.Snapshot())
+}
+
+// Append publishes a copy of the slice with vs appended.
+func (s *COWSlice[T]) Append(vs ...T) {
+	s.Update(func(c []T) []T {
+		return append(c, vs...)
+	})
+}
+
+// Set publishes a copy of the slice with the value at i replaced by v. Panics
+// if i is out of range.
+func (s *COWSlice[T]) Set(i int, v T) {
+	s.Update(func(c []T) []T {
+		c[i] = v
+		return c
+	})
+}
+
+// Delete publishes a copy of the slice with the value at i removed. Panics if i
+// is out of range.
+func (s *COWSlice[T]) Delete(i int) {
+	s.Update(func(c []T) []T {
+		return slices.Delete(c, i, i+1)
+	})
+}
+
+// Update calls f with a copy of the slice and publishes what it returns. f may
+// freely modify its argument. Calls to Update are serialized.
+func (s *COWSlice[T]) Update(f func(c []T) []T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := f(slices.Clone(s.Snapshot()))
+	s.p.Store(&c)
+}
+
+// COWMap is a copy-on-write map. Reads load the current snapshot without
+// locking. Writes clone the snapshot while holding an internal lock and then
+// publish the clone atomically, so readers never see a partial write. It suits
+// read-mostly data such as configuration. The zero value is an empty map that
+// is safe to use. A COWMap must not be copied after first use.
+type COWMap[K comparable, V any] struct {
+	mu sync.Mutex // serializes writers.
+	p  atomic.Pointer[map[K]V]
+}
+
+// Snapshot returns the current map. It is shared with other readers and must
+// not be modified.
+func (m *COWMap[K, V]) Snapshot() map[K]V {
+	if p := m.p.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// Load returns the value stored for k and whether it was present.
+func (m *COWMap[K, V]) Load(k K) (v V, ok bool) {
+	v, ok = m.Snapshot()[k]
+	return v, ok
+}
+
+// Len returns the number of keys in the current map.
+func (m *COWMap[K, V]) Len() int {
+	return len(m.Snapshot())
+}
+
+// All returns an iterator over the keys and values of a consistent snapshot.
+func (m *COWMap[K, V]) All() iter.Seq2[K, V] {
+	return maps.All(m.Snapshot())
+}
+
+// Keys returns an iterator over the keys of a consistent snapshot.
+func (m *COWMap[K, V]) Keys() iter.Seq[K] {
+	return maps.Keys(m.Snapshot())
+}
+
+// Set publishes a copy of the map with k set to v.
+func (m *COWMap[K, V]) Set(k K, v V) {
+	m.Update(func(c map[K]V) {
+		c[k] = v
+	})
+}
+
+// Delete publishes a copy of the map without k.
+func (m *COWMap[K, V]) Delete(k K) {
+	m.Update(func(c map[K]V) {
+		delete(c, k)
+	})
+}
+
+// Update calls f with a copy of the map and publishes it once f returns. f may
+// freely modify its argument. Calls to Update are serialized.
+func (m *COWMap[K, V]) Update(f func(c map[K]V)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := maps.Clone(m.Snapshot())
+	if c == nil {
+		c = make(map[K]V)
+	}
+	f(c)
+	m.p.Store(&c)
+}
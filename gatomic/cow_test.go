@@ -0,0 +1,103 @@
+package gatomic_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func ExampleCOWMap() {
+	var routes gatomic.COWMap[string, string]
+	routes.Set("/", "index")
+	routes.Set("/about", "about")
+
+	// Readers iterate a consistent snapshot even while writers publish new
+	// versions.
+	for _, path := range slices.Sorted(routes.Keys()) {
+		v, _ := routes.Load(path)
+		fmt.Println(path, v)
+	}
+
+	// Output:
+	// / index
+	// /about about
+}
+
+func TestCOWSlice(t *testing.T) {
+	var s gatomic.COWSlice[int]
+	if s.Len() != 0 || s.Snapshot() != nil {
+		t.Fatal("expected empty zero value")
+	}
+	s.Append(1, 2, 3)
+	before := s.Snapshot()
+	s.Set(0, 10)
+	s.Delete(1)
+	if got := s.Snapshot(); !slices.Equal(got, []int{10, 3}) {
+		t.Fatalf("expected [10 3], got %v", got)
+	}
+	if !slices.Equal(before, []int{1, 2, 3}) {
+		t.Fatalf("expected earlier snapshot to be unchanged, got %v", before)
+	}
+	s.Update(func(c []int) []int {
+		return append(c[:0], 4)
+	})
+	if got := slices.Collect(s.Values()); !slices.Equal(got, []int{4}) {
+		t.Fatalf("expected [4], got %v", got)
+	}
+	for i, v := range s.All() {
+		if i != 0 || v != 4 {
+			t.Fatalf("expected 0, 4, got %d, %d", i, v)
+		}
+	}
+}
+
+func TestCOWMap(t *testing.T) {
+	var m gatomic.COWMap[string, int]
+	if _, ok := m.Load("a"); ok || m.Len() != 0 {
+		t.Fatal("expected empty zero value")
+	}
+	m.Set("a", 1)
+	m.Set("b", 2)
+	before := m.Snapshot()
+	m.Delete("a")
+	m.Update(func(c map[string]int) {
+		c["b"]++
+	})
+	if got := maps.Collect(m.All()); !maps.Equal(got, map[string]int{"b": 3}) {
+		t.Fatalf("expected map[b:3], got %v", got)
+	}
+	if !maps.Equal(before, map[string]int{"a": 1, "b": 2}) {
+		t.Fatalf("expected earlier snapshot to be unchanged, got %v", before)
+	}
+	if v, ok := m.Load("b"); !ok || v != 3 {
+		t.Fatalf("expected 3, true, got %d, %t", v, ok)
+	}
+}
+
+// must be tested with "-race"
+func TestCOW_race(t *testing.T) {
+	var s gatomic.COWSlice[int]
+	var m gatomic.COWMap[int, int]
+	n := 100
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			s.Append(i)
+			m.Set(i, i)
+		})
+		wg.Go(func() {
+			for range s.Values() {
+			}
+			for range m.All() {
+			}
+		})
+	}
+	wg.Wait()
+	if s.Len() != n || m.Len() != n {
+		t.Fatalf("expected %d items, got %d and %d", n, s.Len(), m.Len())
+	}
+}
@@ -1,2 +1,3 @@
-// Package gatomic provides generic wrappers around [sync/atomic] operations.
+// Package gatomic provides generic wrappers around [sync/atomic] operations and
+// lock-free data structures built on them.
 package gatomic
@@ -0,0 +1,67 @@
+package gatomic
+
+import "sync/atomic"
+
+// LinkedQueue is an unbounded lock-free FIFO queue, also known as a
+// Michael-Scott queue. Nodes are never reused, so the garbage collector rules
+// out the ABA problem. The zero value is an empty queue that is safe to use.
+type LinkedQueue[T any] struct {
+	// head points at a dummy node whose successor is the front of the queue.
+	head atomic.Pointer[queueNode[T]]
+	tail atomic.Pointer[queueNode[T]]
+}
+
+type queueNode[T any] struct {
+	v    T
+	next atomic.Pointer[queueNode[T]]
+}
+
+// init replaces the initial nil head and tail with a dummy node.
+func (q *LinkedQueue[T]) init() {
+	if q.tail.Load() != nil {
+		return
+	}
+	q.head.CompareAndSwap(nil, &queueNode[T]{})
+	q.tail.CompareAndSwap(nil, q.head.Load())
+}
+
+// Enqueue adds v to the back of q.
+func (q *LinkedQueue[T]) Enqueue(v T) {
+	q.init()
+	n := &queueNode[T]{v: v}
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if next != nil {
+			// tail is lagging behind, help move it along.
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if tail.next.CompareAndSwap(nil, n) {
+			q.tail.CompareAndSwap(tail, n)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the item at the front of q and reports whether q
+// had one.
+func (q *LinkedQueue[T]) Dequeue() (v T, ok bool) {
+	q.init()
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if next == nil {
+			return v, false
+		}
+		if head == tail {
+			// tail is lagging behind, help move it along.
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+		if q.head.CompareAndSwap(head, next) {
+			return next.v, true
+		}
+	}
+}
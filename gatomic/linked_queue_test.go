@@ -0,0 +1,113 @@
+package gatomic_test
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func TestLinkedQueue(t *testing.T) {
+	var q gatomic.LinkedQueue[int]
+	if _, ok := q.Dequeue(); ok {
+		t.Fatal("expected empty queue")
+	}
+	for i := range 3 {
+		q.Enqueue(i)
+	}
+	for i := range 3 {
+		if v, ok := q.Dequeue(); !ok || v != i {
+			t.Fatalf("expected %d, true, got %d, %t", i, v, ok)
+		}
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Fatal("expected empty queue")
+	}
+	q.Enqueue(3)
+	if v, ok := q.Dequeue(); !ok || v != 3 {
+		t.Fatalf("expected 3, true, got %d, %t", v, ok)
+	}
+}
+
+// Checks that every item is dequeued exactly once and in the order each
+// producer enqueued it. Must be tested with "-race".
+func TestLinkedQueue_race(t *testing.T) {
+	const producers, perProducer = 4, 1000
+	type item struct{ producer, seq int }
+	var q gatomic.LinkedQueue[item]
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProducer {
+				q.Enqueue(item{p, i})
+			}
+		})
+	}
+	last := make([]int, producers)
+	for p := range last {
+		last[p] = -1
+	}
+	for range producers * perProducer {
+		for {
+			v, ok := q.Dequeue()
+			if !ok {
+				runtime.Gosched()
+				continue
+			}
+			if v.seq != last[v.producer]+1 {
+				t.Fatalf("producer %d item %d dequeued after %d", v.producer, v.seq, last[v.producer])
+			}
+			last[v.producer] = v.seq
+			break
+		}
+	}
+	wg.Wait()
+	if _, ok := q.Dequeue(); ok {
+		t.Fatal("expected empty queue")
+	}
+}
+
+// mutexQueue is a mutex protected slice used as a baseline in benchmarks.
+type mutexQueue[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (q *mutexQueue[T]) Enqueue(v T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items = append(q.items, v)
+}
+
+func (q *mutexQueue[T]) Dequeue() (v T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return v, false
+	}
+	v = q.items[0]
+	q.items = q.items[1:]
+	return v, true
+}
+
+func BenchmarkLinkedQueue(b *testing.B) {
+	benchmarkProcs(b, "lockfree", func(b *testing.B) {
+		var q gatomic.LinkedQueue[int]
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Enqueue(1)
+				q.Dequeue()
+			}
+		})
+	})
+	benchmarkProcs(b, "mutex", func(b *testing.B) {
+		var q mutexQueue[int]
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Enqueue(1)
+				q.Dequeue()
+			}
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package gatomic
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// RCU publishes versions of a value in read-copy-update style. Like [Value],
+// readers load the current version without locking and writers publish a new
+// one. On top of that, RCU tracks which readers are still using each version,
+// so resources held by a replaced version can be released safely once its
+// last reader exits. The zero value holds the zero value of T and is safe to
+// use. An RCU must not be copied after first use.
+//
+//	s := idx.Enter()
+//	defer s.Exit()
+//	s.Value().Lookup(key)
+type RCU[T any] struct {
+	// Reclaim is optionally called with each replaced value once no reader can
+	// observe it. It is not called for the zero value read before the first
+	// Publish. Must be set before first use.
+	Reclaim func(T)
+
+	cur     atomic.Pointer[RCUSnapshot[T]]
+	mu      sync.Mutex // guards retired.
+	retired map[*RCUSnapshot[T]]struct{}
+}
+
+// RCUSnapshot is a version of an [RCU] value held by a reader between
+// [RCU.Enter] and [RCUSnapshot.Exit].
+type RCUSnapshot[T any] struct {
+	v T
+	// refs counts readers, plus one while the version is current. The version
+	// is reclaimed when it drops to zero, and it is never raised from zero.
+	refs atomic.Int64
+	// done is closed once the version has been reclaimed.
+	done chan struct{}
+	rcu  *RCU[T]
+	// implicit marks the zero value version created before the first
+	// Publish. It was never published, so it is never passed to Reclaim.
+	implicit bool
+}
+
+// init replaces the initial nil version with a zero value T.
+func (r *RCU[T]) init() {
+	if r.cur.Load() == nil {
+		var x T
+		s := r.snapshot(x)
+		s.implicit = true
+		r.cur.CompareAndSwap(nil, s)
+	}
+}
+
+// snapshot returns a new current version of v.
+func (r *RCU[T]) snapshot(v T) *RCUSnapshot[T] {
+	s := &RCUSnapshot[T]{v: v, done: make(chan struct{}), rcu: r}
+	s.refs.Store(1)
+	return s
+}
+
+// Load returns the current value without entering a read-side critical
+// section, so the value may be reclaimed while in use.
+func (r *RCU[T]) Load() T {
+	r.init()
+	return r.cur.Load().v
+}
+
+// Enter starts a read-side critical section and returns the current version.
+// The version will not be reclaimed until [RCUSnapshot.Exit] is called.
+func (r *RCU[T]) Enter() *RCUSnapshot[T] {
+	r.init()
+	for {
+		s := r.cur.Load()
+		// a version with no refs has been replaced, so load the new one.
+		if n := s.refs.Load(); n > 0 && s.refs.CompareAndSwap(n, n+1) {
+			return s
+		}
+	}
+}
+
+// Read calls f with the current value inside a read-side critical section.
+// Short for calling [RCU.Enter] and [RCUSnapshot.Exit].
+func (r *RCU[T]) Read(f func(v T)) {
+	s := r.Enter()
+	defer s.Exit()
+	f(s.v)
+}
+
+// Publish makes v the current value. The replaced value is reclaimed once all
+// readers that entered before Publish have exited.
+func (r *RCU[T]) Publish(v T) {
+	old := r.cur.Swap(r.snapshot(v))
+	if old == nil {
+		return
+	}
+	r.mu.Lock()
+	if r.retired == nil {
+		r.retired = make(map[*RCUSnapshot[T]]struct{})
+	}
+	r.retired[old] = struct{}{}
+	r.mu.Unlock()
+	old.release()
+}
+
+// Synchronize waits until every value replaced before it was called has been
+// reclaimed, or returns ctx's error if it is done first.
+func (r *RCU[T]) Synchronize(ctx context.Context) error {
+	r.mu.Lock()
+	waits := make([]chan struct{}, 0, len(r.retired))
+	for s := range r.retired {
+		waits = append(waits, s.done)
+	}
+	r.mu.Unlock()
+	for _, done := range waits {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+		}
+	}
+	return nil
+}
+
+// Value returns the value of the version.
+func (s *RCUSnapshot[T]) Value() T {
+	return s.v
+}
+
+// Exit ends the read-side critical section started by [RCU.Enter]. Panics if
+// called more often than Enter.
+func (s *RCUSnapshot[T]) Exit() {
+	s.release()
+}
+
+// release drops a reference, reclaiming the version when it was the last one.
+func (s *RCUSnapshot[T]) release() {
+	switch n := s.refs.Add(-1); {
+	case n < 0:
+		panic("gatomic: RCU snapshot exited more often than entered")
+	case n == 0:
+		r := s.rcu
+		r.mu.Lock()
+		delete(r.retired, s)
+		r.mu.Unlock()
+		if r.Reclaim != nil && !s.implicit {
+			r.Reclaim(s.v)
+		}
+		close(s.done)
+	}
+}
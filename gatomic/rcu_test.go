@@ -0,0 +1,157 @@
+package gatomic_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func TestRCULoad(t *testing.T) {
+	var r gatomic.RCU[string]
+	if v := r.Load(); v != "" {
+		t.Fatalf("expected zero value, got %q", v)
+	}
+	r.Publish("a")
+	if v := r.Load(); v != "a" {
+		t.Fatalf("expected a, got %q", v)
+	}
+}
+
+func TestRCUEnter(t *testing.T) {
+	var reclaimed []string
+	r := gatomic.RCU[string]{Reclaim: func(v string) {
+		reclaimed = append(reclaimed, v)
+	}}
+	r.Publish("a")
+	s := r.Enter()
+	r.Publish("b")
+	if v := s.Value(); v != "a" {
+		t.Fatalf("expected reader to keep seeing a, got %q", v)
+	}
+	if len(reclaimed) != 0 {
+		t.Fatalf("expected nothing reclaimed while a reader is inside, got %v", reclaimed)
+	}
+	s.Exit()
+	if !slices.Equal(reclaimed, []string{"a"}) {
+		t.Fatalf("expected [a] reclaimed, got %v", reclaimed)
+	}
+	r.Read(func(v string) {
+		if v != "b" {
+			t.Fatalf("expected b, got %q", v)
+		}
+	})
+}
+
+func TestRCUPublish_does_not_reclaim_implicit_zero_value(t *testing.T) {
+	var reclaimed []*int
+	r := gatomic.RCU[*int]{Reclaim: func(v *int) {
+		reclaimed = append(reclaimed, v)
+	}}
+	r.Load()
+	r.Read(func(*int) {})
+	a := new(int)
+	r.Publish(a)
+	if len(reclaimed) != 0 {
+		t.Fatalf("expected the unpublished zero value not to be reclaimed, got %v", reclaimed)
+	}
+	r.Publish(new(int))
+	if !slices.Equal(reclaimed, []*int{a}) {
+		t.Fatalf("expected only the published value to be reclaimed, got %v", reclaimed)
+	}
+}
+
+func TestRCUExit_panics_when_exited_twice(t *testing.T) {
+	var r gatomic.RCU[int]
+	s := r.Enter()
+	r.Publish(1)
+	s.Exit()
+	defer func() {
+		if v := recover(); v == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	s.Exit()
+}
+
+func TestRCUSynchronize(t *testing.T) {
+	t.Run("returns once readers exit", func(t *testing.T) {
+		var r gatomic.RCU[int]
+		s := r.Enter()
+		r.Publish(1)
+		errs := make(chan error)
+		go func() {
+			errs <- r.Synchronize(t.Context())
+		}()
+		select {
+		case <-errs:
+			t.Fatal("Synchronize returned while a reader was inside")
+		default:
+		}
+		s.Exit()
+		if err := <-errs; err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+	t.Run("returns immediately without readers", func(t *testing.T) {
+		var r gatomic.RCU[int]
+		r.Publish(1)
+		r.Publish(2)
+		if err := r.Synchronize(t.Context()); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var r gatomic.RCU[int]
+		r.Enter()
+		r.Publish(1)
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if err := r.Synchronize(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+// Every published value must be reclaimed exactly once, and never while a
+// reader can still see it. Must be tested with "-race".
+func TestRCU_race(t *testing.T) {
+	type resource struct{ closed bool }
+	var mu sync.Mutex
+	reclaimed := 0
+	r := gatomic.RCU[*resource]{Reclaim: func(v *resource) {
+		mu.Lock()
+		defer mu.Unlock()
+		v.closed = true
+		reclaimed++
+	}}
+	n := 100
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			r.Publish(&resource{})
+		})
+		wg.Go(func() {
+			r.Read(func(v *resource) {
+				if v == nil {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if v.closed {
+					t.Error("read a reclaimed value")
+				}
+			})
+		})
+	}
+	wg.Wait()
+	if err := r.Synchronize(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed != n-1 {
+		t.Fatalf("expected %d values reclaimed, got %d", n-1, reclaimed)
+	}
+}
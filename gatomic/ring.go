@@ -0,0 +1,143 @@
+package gatomic
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// cacheLine is used to pad hot fields onto their own cache lines.
+const cacheLine = 64
+
+// Ring is a bounded multi-producer multi-consumer lock-free queue, based on
+// Dmitry Vyukov's bounded MPMC queue. Each slot carries a sequence number that
+// tells producers and consumers whose turn it is, so enqueue and dequeue only
+// contend on a single compare-and-swap each. Create one with [NewRing].
+type Ring[T any] struct {
+	_     [cacheLine]byte
+	tail  atomic.Uint64 // next position to enqueue.
+	_     [cacheLine - 8]byte
+	head  atomic.Uint64 // next position to dequeue.
+	_     [cacheLine - 8]byte
+	mask  uint64
+	cells []ringCell[T]
+	// notEmpty and notFull must be buffered channels with a capacity of 1. They
+	// wake goroutines blocked in [Ring.DequeueContext] and
+	// [Ring.EnqueueContext].
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+type ringCell[T any] struct {
+	seq atomic.Uint64
+	v   T
+}
+
+// NewRing returns an empty ring that holds at least size items. size is rounded
+// up to a power of 2.
+func NewRing[T any](size int) *Ring[T] {
+	n := 2
+	for n < size {
+		n <<= 1
+	}
+	r := &Ring[T]{
+		mask:     uint64(n - 1),
+		cells:    make([]ringCell[T], n),
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+	for i := range r.cells {
+		r.cells[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// Cap returns the number of items r can hold.
+func (r *Ring[T]) Cap() int {
+	return len(r.cells)
+}
+
+// TryEnqueue adds v to the back of r and reports whether there was room.
+func (r *Ring[T]) TryEnqueue(v T) bool {
+	pos := r.tail.Load()
+	for {
+		c := &r.cells[pos&r.mask]
+		seq := c.seq.Load()
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				c.v = v
+				c.seq.Store(pos + 1)
+				notify(r.notEmpty)
+				return true
+			}
+		case dif < 0:
+			return false
+		}
+		pos = r.tail.Load()
+	}
+}
+
+// TryDequeue removes and returns the item at the front of r and reports
+// whether r had one.
+func (r *Ring[T]) TryDequeue() (v T, ok bool) {
+	pos := r.head.Load()
+	for {
+		c := &r.cells[pos&r.mask]
+		seq := c.seq.Load()
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				var zero T
+				v, c.v = c.v, zero
+				c.seq.Store(pos + r.mask + 1)
+				notify(r.notFull)
+				return v, true
+			}
+		case dif < 0:
+			return v, false
+		}
+		pos = r.head.Load()
+	}
+}
+
+// EnqueueContext adds v to the back of r, blocking while r is full. Returns
+// ctx's error if it is done first.
+func (r *Ring[T]) EnqueueContext(ctx context.Context, v T) error {
+	for !r.TryEnqueue(v) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.notFull:
+		}
+	}
+	// pass the wake up on in case there is still room for others.
+	notify(r.notFull)
+	return nil
+}
+
+// DequeueContext removes and returns the item at the front of r, blocking while
+// r is empty. Returns ctx's error if it is done first.
+func (r *Ring[T]) DequeueContext(ctx context.Context) (T, error) {
+	for {
+		if v, ok := r.TryDequeue(); ok {
+			// pass the wake up on in case there are items left for others.
+			notify(r.notEmpty)
+			return v, nil
+		}
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case <-r.notEmpty:
+		}
+	}
+}
+
+// notify wakes one goroutine waiting on ch without blocking. ch must be
+// buffered so that a wake up is not lost when nobody is waiting yet.
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,204 @@
+package gatomic_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func ExampleRing() {
+	r := gatomic.NewRing[string](4)
+	r.TryEnqueue("hello")
+	r.TryEnqueue("world")
+	for {
+		v, ok := r.TryDequeue()
+		if !ok {
+			break
+		}
+		fmt.Println(v)
+	}
+	// Output:
+	// hello
+	// world
+}
+
+func TestNewRing(t *testing.T) {
+	for size, want := range map[int]int{0: 2, 1: 2, 2: 2, 3: 4, 8: 8, 9: 16} {
+		if got := gatomic.NewRing[int](size).Cap(); got != want {
+			t.Fatalf("expected capacity %d for size %d, got %d", want, size, got)
+		}
+	}
+}
+
+func TestRingTryEnqueueDequeue(t *testing.T) {
+	r := gatomic.NewRing[int](4)
+	if _, ok := r.TryDequeue(); ok {
+		t.Fatal("expected empty ring")
+	}
+	// go around the ring a few times.
+	for round := range 3 {
+		for i := range 4 {
+			if !r.TryEnqueue(round*4 + i) {
+				t.Fatalf("failed to enqueue item %d", i)
+			}
+		}
+		if r.TryEnqueue(-1) {
+			t.Fatal("expected full ring")
+		}
+		for i := range 4 {
+			v, ok := r.TryDequeue()
+			if !ok || v != round*4+i {
+				t.Fatalf("expected %d, true, got %d, %t", round*4+i, v, ok)
+			}
+		}
+		if _, ok := r.TryDequeue(); ok {
+			t.Fatal("expected empty ring")
+		}
+	}
+}
+
+func TestRingEnqueueContext(t *testing.T) {
+	t.Run("returns context error when full", func(t *testing.T) {
+		r := gatomic.NewRing[int](2)
+		r.TryEnqueue(1)
+		r.TryEnqueue(2)
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if err := r.EnqueueContext(ctx, 3); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+	t.Run("blocks until dequeue", func(t *testing.T) {
+		r := gatomic.NewRing[int](2)
+		r.TryEnqueue(1)
+		r.TryEnqueue(2)
+		go func() {
+			time.Sleep(time.Millisecond)
+			r.TryDequeue()
+		}()
+		if err := r.EnqueueContext(t.Context(), 3); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+}
+
+func TestRingDequeueContext(t *testing.T) {
+	t.Run("returns context error when empty", func(t *testing.T) {
+		r := gatomic.NewRing[int](2)
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := r.DequeueContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+	t.Run("blocks until enqueue", func(t *testing.T) {
+		r := gatomic.NewRing[int](2)
+		go func() {
+			time.Sleep(time.Millisecond)
+			r.TryEnqueue(1)
+		}()
+		if v, err := r.DequeueContext(t.Context()); err != nil || v != 1 {
+			t.Fatalf("expected 1, nil, got %d, %v", v, err)
+		}
+	})
+}
+
+// Checks that every item is dequeued exactly once and that each consumer sees
+// each producer's items in the order they were enqueued, which any
+// linearizable FIFO queue must guarantee. Must be tested with "-race".
+func TestRing_linearizable(t *testing.T) {
+	const producers, consumers, perProducer = 4, 4, 1000
+	type item struct{ producer, seq int }
+	r := gatomic.NewRing[item](8)
+	ctx := t.Context()
+
+	var wg sync.WaitGroup
+	for p := range producers {
+		wg.Go(func() {
+			for i := range perProducer {
+				if err := r.EnqueueContext(ctx, item{p, i}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		})
+	}
+	seen := make([][]item, consumers)
+	for c := range consumers {
+		wg.Go(func() {
+			for range producers * perProducer / consumers {
+				v, err := r.DequeueContext(ctx)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				seen[c] = append(seen[c], v)
+			}
+		})
+	}
+	wg.Wait()
+
+	count := make([][]int, producers)
+	for p := range count {
+		count[p] = make([]int, perProducer)
+	}
+	for c, items := range seen {
+		last := make([]int, producers)
+		for p := range last {
+			last[p] = -1
+		}
+		for _, v := range items {
+			if v.seq <= last[v.producer] {
+				t.Fatalf("consumer %d saw producer %d item %d after %d", c, v.producer, v.seq, last[v.producer])
+			}
+			last[v.producer] = v.seq
+			count[v.producer][v.seq]++
+		}
+	}
+	for p := range count {
+		for i, n := range count[p] {
+			if n != 1 {
+				t.Fatalf("producer %d item %d dequeued %d times", p, i, n)
+			}
+		}
+	}
+}
+
+func BenchmarkRing(b *testing.B) {
+	r := gatomic.NewRing[int](1024)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.TryEnqueue(1)
+			r.TryDequeue()
+		}
+	})
+}
+
+func BenchmarkRingContext(b *testing.B) {
+	r := gatomic.NewRing[int](1024)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			r.EnqueueContext(ctx, 1)
+			r.DequeueContext(ctx)
+		}
+	})
+}
+
+func BenchmarkChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ch <- 1
+			<-ch
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package gatomic
+
+import (
+	"encoding/binary"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// SeqLock holds a value that is read optimistically. Readers copy the value
+// without taking a lock and retry if a writer changed it in the meantime, so
+// reads never block each other or writers. Store does not allocate, unlike
+// [Value]. It suits small, frequently read and rarely written values such as
+// a clock offset or a block of counters.
+//
+// The value is copied word by word with atomic operations, which keeps the race
+// detector happy but means T must not contain pointers, strings, slices, maps,
+// channels, funcs or interfaces. Using such a T panics. The zero value holds
+// the zero value of T and is safe to use. A SeqLock must not be copied after
+// first use.
+type SeqLock[T any] struct {
+	once sync.Once
+	mu   sync.Mutex // serializes writers.
+	// seq is odd while a write is in progress.
+	seq   atomic.Uint64
+	words []atomic.Uint64
+}
+
+// init allocates words for T. Panics if T contains pointers.
+func (s *SeqLock[T]) init() {
+	s.once.Do(func() {
+		if hasPointers(reflect.TypeFor[T]()) {
+			panic("gatomic: SeqLock value must not contain pointers")
+		}
+		var v T
+		s.words = make([]atomic.Uint64, (unsafe.Sizeof(v)+7)/8)
+	})
+}
+
+// Load returns the current value, retrying while a write is in progress.
+func (s *SeqLock[T]) Load() (v T) {
+	s.init()
+	b := bytesOf(&v)
+	for {
+		seq := s.seq.Load()
+		if seq&1 == 0 {
+			for i := range s.words {
+				putWord(b, i, s.words[i].Load())
+			}
+			if s.seq.Load() == seq {
+				return v
+			}
+		}
+		runtime.Gosched()
+	}
+}
+
+// Store stores a value, replacing any current value.
+func (s *SeqLock[T]) Store(v T) {
+	s.init()
+	b := bytesOf(&v)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq.Add(1)
+	for i := range s.words {
+		s.words[i].Store(getWord(b, i))
+	}
+	s.seq.Add(1)
+}
+
+// bytesOf returns the memory of v as bytes.
+func bytesOf[T any](v *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(v)), unsafe.Sizeof(*v))
+}
+
+// getWord reads the i-th 8 byte word of b, zero padding the last one.
+func getWord(b []byte, i int) uint64 {
+	b = b[i*8:]
+	if len(b) < 8 {
+		var w [8]byte
+		copy(w[:], b)
+		b = w[:]
+	}
+	return binary.NativeEndian.Uint64(b)
+}
+
+// putWord writes w as the i-th 8 byte word of b, truncating the last one.
+func putWord(b []byte, i int, w uint64) {
+	b = b[i*8:]
+	if len(b) < 8 {
+		var buf [8]byte
+		binary.NativeEndian.PutUint64(buf[:], w)
+		copy(b, buf[:])
+		return
+	}
+	binary.NativeEndian.PutUint64(b, w)
+}
+
+// hasPointers reports whether values of t contain anything the garbage
+// collector needs to trace.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,132 @@
+package gatomic_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+type stats struct {
+	A, B, C int64
+	D       bool
+	E       [3]uint8 // makes the size not a multiple of 8 bytes.
+}
+
+func TestSeqLock(t *testing.T) {
+	var s gatomic.SeqLock[stats]
+	if v := s.Load(); v != (stats{}) {
+		t.Fatalf("expected zero value, got %+v", v)
+	}
+	want := stats{A: 1, B: -2, C: 3, D: true, E: [3]uint8{4, 5, 6}}
+	s.Store(want)
+	if got := s.Load(); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestSeqLock_small(t *testing.T) {
+	var s gatomic.SeqLock[time.Duration]
+	s.Store(time.Second)
+	if got := s.Load(); got != time.Second {
+		t.Fatalf("expected 1s, got %v", got)
+	}
+	var b gatomic.SeqLock[uint8]
+	b.Store(255)
+	if got := b.Load(); got != 255 {
+		t.Fatalf("expected 255, got %d", got)
+	}
+}
+
+func TestSeqLock_panics_with_pointers(t *testing.T) {
+	for name, f := range map[string]func(){
+		"pointer": func() { new(gatomic.SeqLock[*int]).Load() },
+		"string":  func() { new(gatomic.SeqLock[string]).Load() },
+		"struct":  func() { new(gatomic.SeqLock[struct{ A []int }]).Store(struct{ A []int }{}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if v := recover(); v == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			f()
+		})
+	}
+}
+
+// Readers must never see a torn value whose fields were written by different
+// stores. Must be tested with "-race".
+func TestSeqLock_race(t *testing.T) {
+	var s gatomic.SeqLock[stats]
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			for j := range 1000 {
+				n := int64(i*1000 + j)
+				s.Store(stats{A: n, B: n, C: n})
+			}
+		})
+		wg.Go(func() {
+			for range 1000 {
+				if v := s.Load(); v.A != v.B || v.B != v.C {
+					t.Errorf("torn read %+v", v)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func BenchmarkSeqLockLoad(b *testing.B) {
+	var s gatomic.SeqLock[stats]
+	s.Store(stats{A: 1})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.Load()
+		}
+	})
+}
+
+func BenchmarkValueLoad(b *testing.B) {
+	var v gatomic.Value[stats]
+	v.Store(stats{A: 1})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			v.Load()
+		}
+	})
+}
+
+func BenchmarkRWMutexLoad(b *testing.B) {
+	var mu sync.RWMutex
+	v := stats{A: 1}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			mu.RLock()
+			_ = v
+			mu.RUnlock()
+		}
+	})
+}
+
+func BenchmarkSeqLockStore(b *testing.B) {
+	var s gatomic.SeqLock[stats]
+	b.ReportAllocs()
+	for b.Loop() {
+		s.Store(stats{A: 1})
+	}
+}
+
+func BenchmarkValueStore(b *testing.B) {
+	var v gatomic.Value[stats]
+	b.ReportAllocs()
+	for b.Loop() {
+		v.Store(stats{A: 1})
+	}
+}
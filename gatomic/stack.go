@@ -0,0 +1,40 @@
+package gatomic
+
+import "sync/atomic"
+
+// Stack is a lock-free LIFO stack, also known as a Treiber stack. Nodes are
+// never reused, so the garbage collector rules out the ABA problem. The zero
+// value is an empty stack that is safe to use.
+type Stack[T any] struct {
+	top atomic.Pointer[stackNode[T]]
+}
+
+type stackNode[T any] struct {
+	v    T
+	next *stackNode[T]
+}
+
+// Push adds v to the top of s.
+func (s *Stack[T]) Push(v T) {
+	n := &stackNode[T]{v: v}
+	for {
+		n.next = s.top.Load()
+		if s.top.CompareAndSwap(n.next, n) {
+			return
+		}
+	}
+}
+
+// Pop removes and returns the item at the top of s and reports whether s had
+// one.
+func (s *Stack[T]) Pop() (v T, ok bool) {
+	for {
+		top := s.top.Load()
+		if top == nil {
+			return v, false
+		}
+		if s.top.CompareAndSwap(top, top.next) {
+			return top.v, true
+		}
+	}
+}
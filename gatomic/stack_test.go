@@ -0,0 +1,119 @@
+package gatomic_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func TestStack(t *testing.T) {
+	var s gatomic.Stack[int]
+	if _, ok := s.Pop(); ok {
+		t.Fatal("expected empty stack")
+	}
+	for i := range 3 {
+		s.Push(i)
+	}
+	for i := 2; i >= 0; i-- {
+		if v, ok := s.Pop(); !ok || v != i {
+			t.Fatalf("expected %d, true, got %d, %t", i, v, ok)
+		}
+	}
+	if _, ok := s.Pop(); ok {
+		t.Fatal("expected empty stack")
+	}
+}
+
+// must be tested with "-race"
+func TestStack_race(t *testing.T) {
+	var s gatomic.Stack[int]
+	n := 1000
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			s.Push(i)
+		})
+	}
+	popped := make(chan int, n)
+	for range n {
+		wg.Go(func() {
+			for {
+				if v, ok := s.Pop(); ok {
+					popped <- v
+					return
+				}
+				runtime.Gosched()
+			}
+		})
+	}
+	wg.Wait()
+	close(popped)
+	seen := make(map[int]bool)
+	for v := range popped {
+		if seen[v] {
+			t.Fatalf("item %d popped twice", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d items, got %d", n, len(seen))
+	}
+}
+
+// mutexStack is a mutex protected slice used as a baseline in benchmarks.
+type mutexStack[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (s *mutexStack[T]) Push(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = append(s.items, v)
+}
+
+func (s *mutexStack[T]) Pop() (v T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.items) == 0 {
+		return v, false
+	}
+	v = s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return v, true
+}
+
+// benchmarkProcs runs f as a sub-benchmark for a range of GOMAXPROCS settings.
+func benchmarkProcs(b *testing.B, name string, f func(b *testing.B)) {
+	for _, procs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("procs=%d/%s", procs, name), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			b.ReportAllocs()
+			f(b)
+		})
+	}
+}
+
+func BenchmarkStack(b *testing.B) {
+	benchmarkProcs(b, "lockfree", func(b *testing.B) {
+		var s gatomic.Stack[int]
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(1)
+				s.Pop()
+			}
+		})
+	})
+	benchmarkProcs(b, "mutex", func(b *testing.B) {
+		var s mutexStack[int]
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				s.Push(1)
+				s.Pop()
+			}
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package gatomic
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Versioned pairs a value with a version that increases by one on every store.
+// Compare-and-swap compares versions rather than values, so it works for any
+// T and is not fooled by a value changing and changing back. The zero value
+// holds the zero value of T at version 0 and is safe to use.
+type Versioned[T any] struct {
+	p atomic.Pointer[version[T]]
+}
+
+// version is an immutable value and version pair.
+type version[T any] struct {
+	v T
+	n uint64
+	// replaced is closed once a newer version is stored.
+	replaced chan struct{}
+}
+
+// load returns the current version. Initial version is a zero value T.
+func (v *Versioned[T]) load() *version[T] {
+	if cur := v.p.Load(); cur != nil {
+		return cur
+	}
+	v.p.CompareAndSwap(nil, &version[T]{replaced: make(chan struct{})})
+	return v.p.Load()
+}
+
+// Load returns the current value and its version.
+func (v *Versioned[T]) Load() (val T, version uint64) {
+	cur := v.load()
+	return cur.v, cur.n
+}
+
+// Store stores a value, replacing any current value, and returns its version.
+func (v *Versioned[T]) Store(val T) (version uint64) {
+	for {
+		old := v.load()
+		if n, ok := v.swap(old, val); ok {
+			return n
+		}
+	}
+}
+
+// CompareAndSwapVersion stores new only if the current version is expected.
+func (v *Versioned[T]) CompareAndSwapVersion(expected uint64, new T) (swapped bool) {
+	old := v.load()
+	if old.n != expected {
+		return false
+	}
+	_, swapped = v.swap(old, new)
+	return swapped
+}
+
+// WaitForVersion blocks until the version is greater than after and returns
+// the value and version at that point, or returns ctx's error if it is done
+// first.
+func (v *Versioned[T]) WaitForVersion(ctx context.Context, after uint64) (val T, version uint64, err error) {
+	for {
+		cur := v.load()
+		if cur.n > after {
+			return cur.v, cur.n, nil
+		}
+		select {
+		case <-ctx.Done():
+			return val, 0, ctx.Err()
+		case <-cur.replaced:
+		}
+	}
+}
+
+// swap replaces old with the next version of val if old is still current.
+func (v *Versioned[T]) swap(old *version[T], val T) (uint64, bool) {
+	next := &version[T]{v: val, n: old.n + 1, replaced: make(chan struct{})}
+	if !v.p.CompareAndSwap(old, next) {
+		return 0, false
+	}
+	close(old.replaced)
+	return next.n, true
+}
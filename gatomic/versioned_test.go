@@ -0,0 +1,88 @@
+package gatomic_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/jakobii/syncx/gatomic"
+)
+
+func TestVersionedLoadStore(t *testing.T) {
+	var v gatomic.Versioned[[]string]
+	if val, n := v.Load(); val != nil || n != 0 {
+		t.Fatalf("expected nil, 0, got %v, %d", val, n)
+	}
+	if n := v.Store([]string{"a"}); n != 1 {
+		t.Fatalf("expected version 1, got %d", n)
+	}
+	if val, n := v.Load(); len(val) != 1 || n != 1 {
+		t.Fatalf("expected [a], 1, got %v, %d", val, n)
+	}
+}
+
+func TestVersionedCompareAndSwapVersion(t *testing.T) {
+	var v gatomic.Versioned[map[string]int]
+	if !v.CompareAndSwapVersion(0, map[string]int{"a": 1}) {
+		t.Fatal("expected swap at version 0")
+	}
+	if v.CompareAndSwapVersion(0, nil) {
+		t.Fatal("expected stale version to fail")
+	}
+	if !v.CompareAndSwapVersion(1, nil) {
+		t.Fatal("expected swap at version 1")
+	}
+	if _, n := v.Load(); n != 2 {
+		t.Fatalf("expected version 2, got %d", n)
+	}
+}
+
+func TestVersionedWaitForVersion(t *testing.T) {
+	t.Run("returns newer version", func(t *testing.T) {
+		var v gatomic.Versioned[int]
+		go v.Store(10)
+		val, n, err := v.WaitForVersion(t.Context(), 0)
+		if err != nil || val != 10 || n != 1 {
+			t.Fatalf("expected 10, 1, nil, got %d, %d, %v", val, n, err)
+		}
+	})
+	t.Run("returns immediately when already newer", func(t *testing.T) {
+		var v gatomic.Versioned[int]
+		v.Store(1)
+		v.Store(2)
+		if _, n, _ := v.WaitForVersion(t.Context(), 1); n != 2 {
+			t.Fatalf("expected version 2, got %d", n)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var v gatomic.Versioned[int]
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, _, err := v.WaitForVersion(ctx, 0); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+// Optimistic read-modify-write loops must not lose updates. Must be tested
+// with "-race".
+func TestVersioned_race(t *testing.T) {
+	var v gatomic.Versioned[int]
+	n := 100
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			for {
+				val, ver := v.Load()
+				if v.CompareAndSwapVersion(ver, val+1) {
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if val, ver := v.Load(); val != n || ver != uint64(n) {
+		t.Fatalf("expected %d at version %d, got %d at version %d", n, n, val, ver)
+	}
+}
@@ -0,0 +1,104 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// Guarded owns a value of type T and only exposes it while holding a [Mutex],
+// which replaces the "protected by mu" comment with something the compiler
+// enforces. Acquiring the lock is cancellable. The zero value holds the zero
+// value of T and is safe to use. A Guarded must not be copied after first use.
+//
+//	var hits syncx.Guarded[map[string]int]
+//	hits.Do(func(m *map[string]int) {
+//	    if *m == nil {
+//	        *m = make(map[string]int)
+//	    }
+//	    (*m)["/"]++
+//	})
+//
+// Pointers to the value must not be retained after f returns.
+type Guarded[T any] struct {
+	mu Mutex
+	v  T
+}
+
+// Do calls f with a pointer to the value while holding the lock.
+func (g *Guarded[T]) Do(f func(v *T)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f(&g.v)
+}
+
+// DoContext calls f with a pointer to the value while holding the lock, or
+// returns ctx's error if the lock could not be acquired.
+func (g *Guarded[T]) DoContext(ctx context.Context, f func(v *T)) error {
+	if err := g.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer g.mu.Unlock()
+	f(&g.v)
+	return nil
+}
+
+// TryDo calls f with a pointer to the value if the lock is free and reports
+// whether it did.
+func (g *Guarded[T]) TryDo(f func(v *T)) bool {
+	if !g.mu.TryLock() {
+		return false
+	}
+	defer g.mu.Unlock()
+	f(&g.v)
+	return true
+}
+
+// Load returns a copy of the value. The copy is shallow, so Load is only a
+// snapshot for types that do not reference shared memory.
+func (g *Guarded[T]) Load() T {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.v
+}
+
+// RWGuarded is like [Guarded] but lets readers access the value in parallel.
+// It is built on [sync.RWMutex], so acquiring the lock is not cancellable. The
+// zero value holds the zero value of T and is safe to use. An RWGuarded must
+// not be copied after first use.
+type RWGuarded[T any] struct {
+	mu sync.RWMutex
+	v  T
+}
+
+// Do calls f with a pointer to the value while holding the write lock.
+func (g *RWGuarded[T]) Do(f func(v *T)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f(&g.v)
+}
+
+// TryDo calls f with a pointer to the value if the write lock is free and
+// reports whether it did.
+func (g *RWGuarded[T]) TryDo(f func(v *T)) bool {
+	if !g.mu.TryLock() {
+		return false
+	}
+	defer g.mu.Unlock()
+	f(&g.v)
+	return true
+}
+
+// Read calls f with the value while holding the read lock. f must not mutate
+// memory referenced by the value.
+func (g *RWGuarded[T]) Read(f func(v T)) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	f(g.v)
+}
+
+// Load returns a shallow copy of the value.
+func (g *RWGuarded[T]) Load() T {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.v
+}
@@ -0,0 +1,114 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGuardedDo(t *testing.T) {
+	var g Guarded[int]
+	g.Do(func(v *int) {
+		if len(g.mu.state()) != 1 {
+			t.Fatal("expected lock to be held")
+		}
+		*v = 123
+	})
+	if len(g.mu.state()) != 0 {
+		t.Fatal("expected lock to be released")
+	}
+	if got := g.Load(); got != 123 {
+		t.Fatalf("expected 123, got %d", got)
+	}
+}
+
+func TestGuardedDoContext(t *testing.T) {
+	t.Run("calls f", func(t *testing.T) {
+		var g Guarded[int]
+		if err := g.DoContext(t.Context(), func(v *int) { *v = 1 }); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if got := g.Load(); got != 1 {
+			t.Fatalf("expected 1, got %d", got)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var g Guarded[int]
+		g.mu.Lock()
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		err := g.DoContext(ctx, func(v *int) {
+			t.Fatal("f should not be called")
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestGuardedTryDo(t *testing.T) {
+	var g Guarded[int]
+	if !g.TryDo(func(v *int) { *v = 1 }) {
+		t.Fatal("expected TryDo to succeed")
+	}
+	g.mu.Lock()
+	if g.TryDo(func(v *int) { t.Fatal("f should not be called") }) {
+		t.Fatal("expected TryDo to fail when locked")
+	}
+	g.mu.Unlock()
+}
+
+// must be tested with "-race"
+func TestGuardedDo_race(t *testing.T) {
+	var g Guarded[int]
+	n := 100
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			g.Do(func(v *int) { *v++ })
+		})
+	}
+	wg.Wait()
+	if got := g.Load(); got != n {
+		t.Fatalf("expected %d, got %d", n, got)
+	}
+}
+
+func TestRWGuarded(t *testing.T) {
+	var g RWGuarded[int]
+	g.Do(func(v *int) { *v = 1 })
+	g.Read(func(v int) {
+		if v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+		if g.TryDo(func(v *int) { t.Fatal("f should not be called") }) {
+			t.Fatal("expected TryDo to fail while reading")
+		}
+	})
+	if !g.TryDo(func(v *int) { *v = 2 }) {
+		t.Fatal("expected TryDo to succeed")
+	}
+	if got := g.Load(); got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+}
+
+// must be tested with "-race"
+func TestRWGuarded_race(t *testing.T) {
+	var g RWGuarded[int]
+	n := 100
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			g.Do(func(v *int) { *v++ })
+		})
+		wg.Go(func() {
+			g.Read(func(v int) { _ = v })
+		})
+	}
+	wg.Wait()
+	if got := g.Load(); got != n {
+		t.Fatalf("expected %d, got %d", n, got)
+	}
+}
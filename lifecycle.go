@@ -0,0 +1,256 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Service is a component managed by a [Lifecycle].
+type Service interface {
+	// Start starts the service. It should return once the service is ready
+	// for its dependents to use.
+	Start(ctx context.Context) error
+	// Stop stops the service.
+	Stop(ctx context.Context) error
+}
+
+// Lifecycle starts services in dependency order and stops them in reverse.
+// Services whose dependencies have started are started in parallel.
+//
+// The zero value is ready to use. A Lifecycle must not be copied after first
+// use.
+//
+//	var lc syncx.Lifecycle
+//	lc.Add("db", db)
+//	lc.Add("cache", cache, "db")
+//	lc.Add("http", server, "db", "cache")
+//	if err := lc.Start(ctx); err != nil {
+//	    return err
+//	}
+//	defer lc.Stop(context.WithoutCancel(ctx))
+type Lifecycle struct {
+	mu       Mutex
+	services []*service
+	byName   map[string]*service
+	// ready holds the channels returned by Ready, closed once their service
+	// has started. They are never reset.
+	ready   map[string]chan struct{}
+	started bool
+	// starting counts the goroutines starting services.
+	starting WaitGroup
+}
+
+type service struct {
+	name       string
+	svc        Service
+	deps       []string
+	dependents []*service
+	// running is set once Start succeeded, until Stop is called.
+	running bool
+}
+
+// Add adds a service named name that depends on the services named deps.
+// Dependencies may be added later, but must all be added before Start. Returns
+// [ErrClosed] if Start has been called.
+func (l *Lifecycle) Add(name string, svc Service, deps ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.started {
+		return ErrClosed
+	}
+	if _, ok := l.byName[name]; ok {
+		return fmt.Errorf("syncx: duplicate service %s", name)
+	}
+	if l.byName == nil {
+		l.byName = make(map[string]*service)
+	}
+	s := &service{name: name, svc: svc, deps: deps}
+	l.services = append(l.services, s)
+	l.byName[name] = s
+	return nil
+}
+
+// Start starts every service once its dependencies have started. If a
+// service fails to start, or ctx is done while it waits for its dependencies,
+// Start waits for the services still starting and stops every started service
+// in reverse dependency order before returning the error. Returns an error wrapping [ErrDependencyCycle] if the
+// dependencies form a cycle, or [ErrClosed] if Start has been called before.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	if l.started {
+		l.mu.Unlock()
+		return ErrClosed
+	}
+	if err := l.resolve(); err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	l.started = true
+	services := l.services
+	ready := make([]chan struct{}, len(services))
+	for i, s := range services {
+		ready[i] = l.readyCh(s.name)
+	}
+	l.mu.Unlock()
+
+	failed := make(chan struct{})
+	fail := sync.OnceFunc(func() {
+		close(failed)
+	})
+	errs := make([]error, len(services))
+	for i, s := range services {
+		l.starting.Go(func() {
+			for _, d := range s.deps {
+				select {
+				case <-l.Ready(d):
+				case <-failed:
+					return
+				case <-ctx.Done():
+					errs[i] = fmt.Errorf("start %s: %w", s.name, ctx.Err())
+					fail()
+					return
+				}
+			}
+			if err := s.svc.Start(ctx); err != nil {
+				errs[i] = fmt.Errorf("start %s: %w", s.name, err)
+				fail()
+				return
+			}
+			l.mu.Lock()
+			s.running = true
+			close(ready[i])
+			l.mu.Unlock()
+		})
+	}
+	l.starting.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return errors.Join(err, l.stop(context.WithoutCancel(ctx)))
+	}
+	return nil
+}
+
+// Stop stops every started service once the services depending on it have
+// stopped. Services that don't depend on each other are stopped in parallel.
+// Stop waits for a Start in progress to return first. Returns the errors of
+// the services that failed to stop.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.starting.Wait()
+	return l.stop(ctx)
+}
+
+// Ready returns a channel that is closed once the service named name has
+// started. The channel is one-shot: it stays closed after the service is
+// stopped, including when a failed Start rolls it back. Panics if no service
+// named name has been added.
+func (l *Lifecycle) Ready(name string) <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.byName[name]; !ok {
+		panic("ready of unknown service " + name)
+	}
+	return l.readyCh(name)
+}
+
+// readyCh returns the ready channel of name. Must hold mu.
+func (l *Lifecycle) readyCh(name string) chan struct{} {
+	if l.ready == nil {
+		l.ready = make(map[string]chan struct{})
+	}
+	ch, ok := l.ready[name]
+	if !ok {
+		ch = make(chan struct{})
+		l.ready[name] = ch
+	}
+	return ch
+}
+
+// stop stops the running services in reverse dependency order.
+func (l *Lifecycle) stop(ctx context.Context) error {
+	l.mu.Lock()
+	services := l.services
+	l.mu.Unlock()
+	stopped := make(map[*service]chan struct{}, len(services))
+	for _, s := range services {
+		stopped[s] = make(chan struct{})
+	}
+	errs := make([]error, len(services))
+	var wg WaitGroup
+	for i, s := range services {
+		wg.Go(func() {
+			defer close(stopped[s])
+			for _, d := range s.dependents {
+				<-stopped[d]
+			}
+			l.mu.Lock()
+			running := s.running
+			s.running = false
+			l.mu.Unlock()
+			if !running {
+				return
+			}
+			if err := s.svc.Stop(ctx); err != nil {
+				errs[i] = fmt.Errorf("stop %s: %w", s.name, err)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// resolve links services to their dependents, and checks that every
+// dependency exists and that there are no cycles. Must hold mu.
+func (l *Lifecycle) resolve() error {
+	for _, s := range l.services {
+		s.dependents = nil
+	}
+	for _, s := range l.services {
+		for _, name := range s.deps {
+			d, ok := l.byName[name]
+			if !ok {
+				return fmt.Errorf("syncx: %s depends on unknown service %s", s.name, name)
+			}
+			d.dependents = append(d.dependents, s)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*service]int, len(l.services))
+	var path []string
+	var visit func(s *service) error
+	visit = func(s *service) error {
+		switch state[s] {
+		case visiting:
+			i := len(path) - 1
+			for path[i] != s.name {
+				i--
+			}
+			cycle := append(path[i:], s.name)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+		path = append(path, s.name)
+		for _, name := range s.deps {
+			if err := visit(l.byName[name]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[s] = visited
+		return nil
+	}
+	for _, s := range l.services {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
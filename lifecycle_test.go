@@ -0,0 +1,234 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeService records its starts and stops in a shared log.
+type fakeService struct {
+	name     string
+	log      *serviceLog
+	startErr error
+	stopErr  error
+	// block, if set, holds Start until it is closed.
+	block chan struct{}
+}
+
+type serviceLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *serviceLog) add(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *serviceLog) index(event string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Index(l.events, event)
+}
+
+func (s *fakeService) Start(ctx context.Context) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if s.startErr != nil {
+		return s.startErr
+	}
+	s.log.add("start " + s.name)
+	return nil
+}
+
+func (s *fakeService) Stop(context.Context) error {
+	s.log.add("stop " + s.name)
+	return s.stopErr
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Run("starts in dependency order and stops in reverse", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		lc.Add("http", &fakeService{name: "http", log: log}, "db", "cache")
+		lc.Add("cache", &fakeService{name: "cache", log: log}, "db")
+		lc.Add("db", &fakeService{name: "db", log: log})
+		if err := lc.Start(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"db", "cache", "http"} {
+			select {
+			case <-lc.Ready(name):
+			default:
+				t.Fatalf("expected %s to be ready", name)
+			}
+		}
+		if err := lc.Stop(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"start db", "start cache", "start http", "stop http", "stop cache", "stop db"}
+		if !slices.Equal(log.events, want) {
+			t.Fatalf("expected %v, got %v", want, log.events)
+		}
+	})
+	t.Run("starts independent services in parallel", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		block := make(chan struct{})
+		lc.Add("slow", &fakeService{name: "slow", log: log, block: block})
+		lc.Add("fast", &fakeService{name: "fast", log: log})
+		lc.Add("app", &fakeService{name: "app", log: log}, "slow", "fast")
+		started := make(chan error)
+		go func() {
+			started <- lc.Start(t.Context())
+		}()
+		<-lc.Ready("fast")
+		select {
+		case <-lc.Ready("app"):
+			t.Fatal("expected app to wait for slow")
+		default:
+		}
+		close(block)
+		if err := <-started; err != nil {
+			t.Fatal(err)
+		}
+		if log.index("start app") < log.index("start slow") {
+			t.Fatal("expected app to start after slow")
+		}
+		lc.Stop(t.Context())
+	})
+	t.Run("rolls back on failure", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		oops := errors.New("oops")
+		lc.Add("db", &fakeService{name: "db", log: log})
+		lc.Add("cache", &fakeService{name: "cache", log: log}, "db")
+		lc.Add("http", &fakeService{name: "http", log: log, startErr: oops}, "cache")
+		lc.Add("api", &fakeService{name: "api", log: log}, "http")
+		err := lc.Start(t.Context())
+		if !errors.Is(err, oops) {
+			t.Fatalf("expected %v, got %v", oops, err)
+		}
+		want := []string{"start db", "start cache", "stop cache", "stop db"}
+		if !slices.Equal(log.events, want) {
+			t.Fatalf("expected %v, got %v", want, log.events)
+		}
+		if err := lc.Stop(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		if len(log.events) != len(want) {
+			t.Fatalf("expected rolled back services not to be stopped again, got %v", log.events)
+		}
+		select {
+		case <-lc.Ready("db"):
+		default:
+			t.Fatal("expected ready channel to stay closed after rollback")
+		}
+	})
+	t.Run("reports stop errors", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		oops := errors.New("oops")
+		lc.Add("db", &fakeService{name: "db", log: log, stopErr: oops})
+		lc.Add("cache", &fakeService{name: "cache", log: log}, "db")
+		lc.Start(t.Context())
+		if err := lc.Stop(t.Context()); !errors.Is(err, oops) {
+			t.Fatalf("expected %v, got %v", oops, err)
+		}
+		if log.index("stop db") < log.index("stop cache") {
+			t.Fatal("expected db to stop after cache")
+		}
+	})
+	t.Run("cancelled start", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		lc.Add("db", &fakeService{name: "db", log: log, block: make(chan struct{})})
+		lc.Add("cache", &fakeService{name: "cache", log: log}, "db")
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if err := lc.Start(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+		if len(log.events) != 0 {
+			t.Fatalf("expected no events, got %v", log.events)
+		}
+	})
+	t.Run("keeps services started despite cancellation", func(t *testing.T) {
+		var lc Lifecycle
+		log := &serviceLog{}
+		lc.Add("db", &fakeService{name: "db", log: log})
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if err := lc.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"start db"}; !slices.Equal(log.events, want) {
+			t.Fatalf("expected %v, got %v", want, log.events)
+		}
+	})
+}
+
+func TestLifecycle_invalid(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		var lc Lifecycle
+		lc.Add("a", &fakeService{}, "b")
+		lc.Add("b", &fakeService{}, "c")
+		lc.Add("c", &fakeService{}, "a")
+		err := lc.Start(t.Context())
+		if !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+		if want := "syncx: dependency cycle: a -> b -> c -> a"; err.Error() != want {
+			t.Fatalf("expected %q, got %q", want, err)
+		}
+	})
+	t.Run("self dependency", func(t *testing.T) {
+		var lc Lifecycle
+		lc.Add("a", &fakeService{}, "a")
+		if err := lc.Start(t.Context()); !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("expected ErrDependencyCycle, got %v", err)
+		}
+	})
+	t.Run("unknown dependency", func(t *testing.T) {
+		var lc Lifecycle
+		lc.Add("a", &fakeService{}, "missing")
+		if err := lc.Start(t.Context()); err == nil {
+			t.Fatal("expected an error for an unknown dependency")
+		}
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		var lc Lifecycle
+		lc.Add("a", &fakeService{})
+		if err := lc.Add("a", &fakeService{}); err == nil {
+			t.Fatal("expected an error for a duplicate service")
+		}
+	})
+	t.Run("ready of unknown service", func(t *testing.T) {
+		var lc Lifecycle
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected Ready to panic for an unknown service")
+			}
+		}()
+		lc.Ready("missing")
+	})
+	t.Run("add after start", func(t *testing.T) {
+		var lc Lifecycle
+		lc.Start(t.Context())
+		if err := lc.Add("a", &fakeService{}); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		if err := lc.Start(t.Context()); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	})
+}
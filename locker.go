@@ -0,0 +1,44 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextLocker is a [sync.Locker] whose locking can be cancelled with a
+// context. Satisfied by [Mutex].
+type ContextLocker interface {
+	sync.Locker
+	LockContext(ctx context.Context) error
+}
+
+// WithLock locks l, calls f and unlocks l. l is unlocked even if f panics.
+// Returns ctx's error if l could not be locked, otherwise f's error.
+//
+// If l is a [ContextLocker], locking is cancelled when ctx is done. Any other
+// [sync.Locker], such as [sync.Mutex] or the read side of a [sync.RWMutex] from
+// [sync.RWMutex.RLocker], blocks until it is locked and ctx is only checked
+// beforehand.
+//
+//	err := syncx.WithLock(ctx, &mu, func() error {
+//	    return save(state)
+//	})
+func WithLock(ctx context.Context, l sync.Locker, f func() error) error {
+	if err := lockContext(ctx, l); err != nil {
+		return err
+	}
+	defer l.Unlock()
+	return f()
+}
+
+// lockContext locks l or returns ctx's error.
+func lockContext(ctx context.Context, l sync.Locker) error {
+	if cl, ok := l.(ContextLocker); ok {
+		return cl.LockContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.Lock()
+	return nil
+}
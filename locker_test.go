@@ -0,0 +1,103 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestWithLock(t *testing.T) {
+	t.Run("locks while calling f", func(t *testing.T) {
+		var mu Mutex
+		err := WithLock(t.Context(), &mu, func() error {
+			if len(mu.state()) != 1 {
+				t.Fatal("expected mutex to be locked while calling f")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(mu.state()) != 0 {
+			t.Fatal("expected mutex to be unlocked after f returns")
+		}
+	})
+	t.Run("returns error of f", func(t *testing.T) {
+		var mu Mutex
+		want := errors.New("oops")
+		if err := WithLock(t.Context(), &mu, func() error { return want }); err != want {
+			t.Fatalf("expected %v, got %v", want, err)
+		}
+		if len(mu.state()) != 0 {
+			t.Fatal("expected mutex to be unlocked after f returns")
+		}
+	})
+	t.Run("unlocks when f panics", func(t *testing.T) {
+		var mu Mutex
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic to propagate")
+			}
+			if len(mu.state()) != 0 {
+				t.Fatal("expected mutex to be unlocked after f panics")
+			}
+		}()
+		WithLock(t.Context(), &mu, func() error { panic("oops") })
+	})
+	t.Run("cancels acquiring a context locker", func(t *testing.T) {
+		var mu Mutex
+		mu.Lock()
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		err := WithLock(ctx, &mu, func() error {
+			t.Fatal("f should not be called")
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+	t.Run("works with sync.Mutex", func(t *testing.T) {
+		var mu sync.Mutex
+		called := false
+		if err := WithLock(t.Context(), &mu, func() error {
+			called = true
+			return nil
+		}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !called {
+			t.Fatal("expected f to be called")
+		}
+		if !mu.TryLock() {
+			t.Fatal("expected sync.Mutex to be unlocked")
+		}
+	})
+	t.Run("checks context before locking a sync.Mutex", func(t *testing.T) {
+		var mu sync.Mutex
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := WithLock(ctx, &mu, func() error {
+			t.Fatal("f should not be called")
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+	t.Run("works with the read side of sync.RWMutex", func(t *testing.T) {
+		var rw sync.RWMutex
+		if err := WithLock(t.Context(), rw.RLocker(), func() error {
+			if rw.TryLock() {
+				t.Fatal("expected read lock to be held")
+			}
+			return nil
+		}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if !rw.TryLock() {
+			t.Fatal("expected read lock to be released")
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package syncx
+
+import (
+	"hash/maphash"
+	"iter"
+	"sync"
+)
+
+// mapShards is the number of lock stripes in a [Map]. Must be a power of 2.
+const mapShards = 64
+
+// Map is a typed concurrent map. Keys are spread over a fixed number of shards
+// that each have their own [Mutex], so operations on different keys rarely
+// contend. The zero value is an empty map that is safe to use. A Map must not
+// be copied after first use.
+type Map[K comparable, V any] struct {
+	once   sync.Once
+	seed   maphash.Seed
+	shards [mapShards]mapShard[K, V]
+}
+
+type mapShard[K comparable, V any] struct {
+	mu Mutex
+	m  map[K]V
+}
+
+// shard gets the shard that owns k with its lock held. Initializes the hash
+// seed if not done so yet.
+func (m *Map[K, V]) shard(k K) *mapShard[K, V] {
+	m.once.Do(func() {
+		m.seed = maphash.MakeSeed()
+	})
+	s := &m.shards[maphash.Comparable(m.seed, k)&(mapShards-1)]
+	s.mu.Lock()
+	return s
+}
+
+// Load returns the value stored for k and whether it was present.
+func (m *Map[K, V]) Load(k K) (v V, ok bool) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	v, ok = s.m[k]
+	return v, ok
+}
+
+// Store sets the value for k.
+func (m *Map[K, V]) Store(k K, v V) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[K]V)
+	}
+	s.m[k] = v
+}
+
+// LoadOrStore returns the existing value for k if present. Otherwise it stores
+// and returns v. loaded reports whether the value was already present.
+func (m *Map[K, V]) LoadOrStore(k K, v V) (actual V, loaded bool) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	if old, ok := s.m[k]; ok {
+		return old, true
+	}
+	if s.m == nil {
+		s.m = make(map[K]V)
+	}
+	s.m[k] = v
+	return v, false
+}
+
+// LoadAndDelete deletes the value for k, returning the previous value if any.
+// loaded reports whether k was present.
+func (m *Map[K, V]) LoadAndDelete(k K) (v V, loaded bool) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	v, loaded = s.m[k]
+	delete(s.m, k)
+	return v, loaded
+}
+
+// Delete deletes the value for k.
+func (m *Map[K, V]) Delete(k K) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	delete(s.m, k)
+}
+
+// Compute atomically updates the value for k. f is called with the current
+// value and whether it was present. If keep is true the returned value is
+// stored, otherwise k is deleted. Compute returns what f returned.
+//
+// f runs while the shard owning k is locked, so it must not call methods on m.
+//
+//	// compute if absent
+//	m.Compute(k, func(old *Conn, ok bool) (*Conn, bool) {
+//	    if ok {
+//	        return old, true
+//	    }
+//	    return dial(k), true
+//	})
+func (m *Map[K, V]) Compute(k K, f func(old V, loaded bool) (v V, keep bool)) (v V, ok bool) {
+	s := m.shard(k)
+	defer s.mu.Unlock()
+	old, loaded := s.m[k]
+	v, ok = f(old, loaded)
+	if !ok {
+		delete(s.m, k)
+		return v, false
+	}
+	if s.m == nil {
+		s.m = make(map[K]V)
+	}
+	s.m[k] = v
+	return v, true
+}
+
+// All returns an iterator over the keys and values in m. Each shard is copied
+// while locked, so the loop body may safely call methods on m. The iteration
+// is not a consistent snapshot of the whole map.
+func (m *Map[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		type entry struct {
+			k K
+			v V
+		}
+		var entries []entry
+		for i := range m.shards {
+			s := &m.shards[i]
+			s.mu.Lock()
+			entries = entries[:0]
+			for k, v := range s.m {
+				entries = append(entries, entry{k, v})
+			}
+			s.mu.Unlock()
+			for _, e := range entries {
+				if !yield(e.k, e.v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Len returns the number of keys in m. Shards are counted one at a time, so
+// concurrent writes may not be reflected.
+func (m *Map[K, V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.Lock()
+		n += len(s.m)
+		s.mu.Unlock()
+	}
+	return n
+}
@@ -0,0 +1,245 @@
+package syncx
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"testing"
+)
+
+func TestMapLoadStore(t *testing.T) {
+	var m Map[string, int]
+	if _, ok := m.Load("a"); ok {
+		t.Fatal("expected missing key in zero value map")
+	}
+	m.Store("a", 1)
+	if v, ok := m.Load("a"); !ok || v != 1 {
+		t.Fatalf("expected 1, true, got %d, %t", v, ok)
+	}
+	m.Store("a", 2)
+	if v, _ := m.Load("a"); v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+}
+
+func TestMapLoadOrStore(t *testing.T) {
+	var m Map[string, int]
+	if v, loaded := m.LoadOrStore("a", 1); loaded || v != 1 {
+		t.Fatalf("expected 1, false, got %d, %t", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore("a", 2); !loaded || v != 1 {
+		t.Fatalf("expected 1, true, got %d, %t", v, loaded)
+	}
+}
+
+func TestMapLoadAndDelete(t *testing.T) {
+	var m Map[string, int]
+	if _, loaded := m.LoadAndDelete("a"); loaded {
+		t.Fatal("expected missing key")
+	}
+	m.Store("a", 1)
+	if v, loaded := m.LoadAndDelete("a"); !loaded || v != 1 {
+		t.Fatalf("expected 1, true, got %d, %t", v, loaded)
+	}
+	if _, ok := m.Load("a"); ok {
+		t.Fatal("expected key to be deleted")
+	}
+}
+
+func TestMapDelete(t *testing.T) {
+	var m Map[string, int]
+	m.Delete("a")
+	m.Store("a", 1)
+	m.Delete("a")
+	if _, ok := m.Load("a"); ok {
+		t.Fatal("expected key to be deleted")
+	}
+}
+
+func TestMapCompute(t *testing.T) {
+	t.Run("stores when absent", func(t *testing.T) {
+		var m Map[string, int]
+		v, ok := m.Compute("a", func(old int, loaded bool) (int, bool) {
+			if loaded {
+				t.Fatal("expected key to be absent")
+			}
+			return 1, true
+		})
+		if !ok || v != 1 {
+			t.Fatalf("expected 1, true, got %d, %t", v, ok)
+		}
+		if v, _ := m.Load("a"); v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	})
+	t.Run("updates when present", func(t *testing.T) {
+		var m Map[string, int]
+		m.Store("a", 1)
+		m.Compute("a", func(old int, loaded bool) (int, bool) {
+			if !loaded || old != 1 {
+				t.Fatalf("expected 1, true, got %d, %t", old, loaded)
+			}
+			return old + 1, true
+		})
+		if v, _ := m.Load("a"); v != 2 {
+			t.Fatalf("expected 2, got %d", v)
+		}
+	})
+	t.Run("deletes when not kept", func(t *testing.T) {
+		var m Map[string, int]
+		m.Store("a", 1)
+		if _, ok := m.Compute("a", func(int, bool) (int, bool) { return 0, false }); ok {
+			t.Fatal("expected ok to be false")
+		}
+		if _, ok := m.Load("a"); ok {
+			t.Fatal("expected key to be deleted")
+		}
+	})
+}
+
+func TestMapAll(t *testing.T) {
+	var m Map[int, int]
+	want := map[int]int{}
+	for i := range 1000 {
+		m.Store(i, i*2)
+		want[i] = i * 2
+	}
+	got := maps.Collect(m.All())
+	if !maps.Equal(got, want) {
+		t.Fatal("expected All to yield every entry")
+	}
+	t.Run("break stops iteration", func(t *testing.T) {
+		n := 0
+		for range m.All() {
+			n++
+			if n == 10 {
+				break
+			}
+		}
+		if n != 10 {
+			t.Fatalf("expected 10 iterations, got %d", n)
+		}
+	})
+	t.Run("body may mutate map", func(t *testing.T) {
+		for k := range m.All() {
+			m.Delete(k)
+		}
+		if n := m.Len(); n != 0 {
+			t.Fatalf("expected empty map, got %d", n)
+		}
+	})
+}
+
+func TestMapLen(t *testing.T) {
+	var m Map[int, int]
+	if n := m.Len(); n != 0 {
+		t.Fatalf("expected 0, got %d", n)
+	}
+	for i := range 100 {
+		m.Store(i, i)
+	}
+	if n := m.Len(); n != 100 {
+		t.Fatalf("expected 100, got %d", n)
+	}
+}
+
+// must be tested with "-race"
+func TestMapCompute_race(t *testing.T) {
+	var m Map[int, int]
+	n := 100
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			for k := range 10 {
+				m.Compute(k, func(old int, _ bool) (int, bool) {
+					return old + 1, true
+				})
+			}
+		})
+	}
+	wg.Wait()
+	for k := range 10 {
+		if v, _ := m.Load(k); v != n {
+			t.Fatalf("expected %d for key %d, got %d", n, k, v)
+		}
+	}
+}
+
+// mutexMap is a single lock map used as a baseline in benchmarks.
+type mutexMap[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]V
+}
+
+func (m *mutexMap[K, V]) Load(k K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.m[k]
+	return v, ok
+}
+
+func (m *mutexMap[K, V]) Store(k K, v V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.m == nil {
+		m.m = make(map[K]V)
+	}
+	m.m[k] = v
+}
+
+// syncMap adapts sync.Map for benchmarks.
+type syncMap[K comparable, V any] struct {
+	m sync.Map
+}
+
+func (m *syncMap[K, V]) Load(k K) (V, bool) {
+	v, ok := m.m.Load(k)
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return v.(V), true
+}
+
+func (m *syncMap[K, V]) Store(k K, v V) {
+	m.m.Store(k, v)
+}
+
+func BenchmarkMap(b *testing.B) {
+	type loadStorer interface {
+		Load(int) (int, bool)
+		Store(int, int)
+	}
+	const keys = 1024
+	impls := []struct {
+		name string
+		new  func() loadStorer
+	}{
+		{"syncx.Map", func() loadStorer { return &Map[int, int]{} }},
+		{"sync.Map", func() loadStorer { return &syncMap[int, int]{} }},
+		{"mutex", func() loadStorer { return &mutexMap[int, int]{} }},
+	}
+	for _, reads := range []int{50, 90, 99} {
+		for _, impl := range impls {
+			b.Run(fmt.Sprintf("reads=%d%%/%s", reads, impl.name), func(b *testing.B) {
+				m := impl.new()
+				for k := range keys {
+					m.Store(k, k)
+				}
+				b.ReportAllocs()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+					for pb.Next() {
+						k := r.IntN(keys)
+						if r.IntN(100) < reads {
+							m.Load(k)
+						} else {
+							m.Store(k, k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -3,6 +3,7 @@ package syncx
 import (
 	"context"
 	"sync"
+	"time"
 )
 
 // Lock is a small helper to make calling struct{}{} a bit less noisy and a
@@ -77,6 +78,49 @@ func (m *Mutex) LockContext(ctx context.Context) error {
 	}
 }
 
+// LockScope locks m or returns ctx's error. On success it returns a function
+// that unlocks m. Calling unlock more than once is a no-op, so it is safe to
+// both defer it and call it early.
+//
+//	unlock, err := mu.LockScope(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer unlock()
+func (m *Mutex) LockScope(ctx context.Context) (unlock func(), err error) {
+	if err := m.LockContext(ctx); err != nil {
+		return nil, err
+	}
+	return sync.OnceFunc(m.Unlock), nil
+}
+
+// LockTimeout locks m and reports whether it succeeded before d elapsed. It
+// does not allocate when the lock is free. Short for calling [Mutex.Acquire].
+func (m *Mutex) LockTimeout(d time.Duration) bool {
+	select {
+	case m.Acquire() <- Lock:
+		return true
+	default:
+	}
+	if d <= 0 {
+		return false
+	}
+	t := getTimer(d)
+	defer putTimer(t)
+	select {
+	case m.Acquire() <- Lock:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// LockDeadline locks m and reports whether it succeeded before t. Short for
+// calling [Mutex.LockTimeout].
+func (m *Mutex) LockDeadline(t time.Time) bool {
+	return m.LockTimeout(time.Until(t))
+}
+
 // state gets the raw chan. Initializes it if not done so yet.
 func (m *Mutex) state() chan struct{} {
 	m.once.Do(func() {
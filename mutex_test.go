@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestLocker(t *testing.T) {
@@ -116,3 +117,137 @@ func TestMutexLock_race(t *testing.T) {
 		t.Fatalf("expected %d locks, got %d", n, i)
 	}
 }
+
+func TestMutexLockScope(t *testing.T) {
+	t.Run("locks and unlocks", func(t *testing.T) {
+		var mu Mutex
+		unlock, err := mu.LockScope(t.Context())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if len(mu.state()) != 1 {
+			t.Fatal("failed to set lock state")
+		}
+		unlock()
+		if len(mu.state()) != 0 {
+			t.Fatal("failed to set unlock state")
+		}
+	})
+	t.Run("unlock is idempotent", func(t *testing.T) {
+		var mu Mutex
+		unlock, err := mu.LockScope(t.Context())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		unlock()
+		unlock()
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var mu Mutex
+		mu.state() <- struct{}{}
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		unlock, err := mu.LockScope(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatal("did not receive context cancel error")
+		}
+		if unlock != nil {
+			t.Fatal("expected nil unlock func on error")
+		}
+	})
+}
+
+func TestMutexLockTimeout(t *testing.T) {
+	t.Run("locks when unlocked", func(t *testing.T) {
+		var mu Mutex
+		if !mu.LockTimeout(time.Second) {
+			t.Fatal("failed to obtain lock")
+		}
+		defer mu.Unlock()
+		if len(mu.state()) != 1 {
+			t.Fatal("failed to set lock state")
+		}
+	})
+	t.Run("times out when locked", func(t *testing.T) {
+		var mu Mutex
+		mu.state() <- struct{}{}
+		if mu.LockTimeout(time.Millisecond) {
+			t.Fatal("obtained lock")
+		}
+	})
+	t.Run("non positive duration acts like try lock", func(t *testing.T) {
+		var mu Mutex
+		if !mu.LockTimeout(0) {
+			t.Fatal("failed to obtain lock")
+		}
+		if mu.LockTimeout(-1) {
+			t.Fatal("obtained lock")
+		}
+	})
+	t.Run("locks once released", func(t *testing.T) {
+		var mu Mutex
+		mu.Lock()
+		go func() {
+			time.Sleep(time.Millisecond)
+			mu.Unlock()
+		}()
+		if !mu.LockTimeout(time.Minute) {
+			t.Fatal("failed to obtain lock")
+		}
+	})
+}
+
+func TestMutexLockDeadline(t *testing.T) {
+	var mu Mutex
+	if !mu.LockDeadline(time.Now().Add(time.Second)) {
+		t.Fatal("failed to obtain lock")
+	}
+	if mu.LockDeadline(time.Now().Add(time.Millisecond)) {
+		t.Fatal("obtained lock")
+	}
+	if mu.LockDeadline(time.Now().Add(-time.Second)) {
+		t.Fatal("obtained lock with a past deadline")
+	}
+}
+
+func BenchmarkMutexLockTimeout(b *testing.B) {
+	b.Run("unlocked", func(b *testing.B) {
+		var mu Mutex
+		b.ReportAllocs()
+		for b.Loop() {
+			mu.LockTimeout(time.Second)
+			mu.Unlock()
+		}
+	})
+	b.Run("locked", func(b *testing.B) {
+		var mu Mutex
+		mu.Lock()
+		b.ReportAllocs()
+		for b.Loop() {
+			mu.LockTimeout(time.Microsecond)
+		}
+	})
+}
+
+func BenchmarkMutexLockContext(b *testing.B) {
+	b.Run("unlocked", func(b *testing.B) {
+		var mu Mutex
+		b.ReportAllocs()
+		for b.Loop() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			mu.LockContext(ctx)
+			mu.Unlock()
+			cancel()
+		}
+	})
+	b.Run("locked", func(b *testing.B) {
+		var mu Mutex
+		mu.Lock()
+		b.ReportAllocs()
+		for b.Loop() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+			mu.LockContext(ctx)
+			cancel()
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package syncx
+
+// notifier wakes goroutines waiting for the next change to some state guarded
+// by a lock. The zero value is ready to use. Its methods must be called with
+// that lock held.
+type notifier struct {
+	// ch is closed and replaced on every change. It is nil when nobody is
+	// waiting.
+	ch chan struct{}
+}
+
+// wait returns a channel that is closed on the next change.
+func (n *notifier) wait() <-chan struct{} {
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+	}
+	return n.ch
+}
+
+// signal wakes everyone waiting for a change.
+func (n *notifier) signal() {
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+	}
+}
@@ -0,0 +1,142 @@
+package syncx
+
+import (
+	"context"
+	"iter"
+	"runtime"
+)
+
+// ParallelMap returns an iterator that calls fn on each value of seq using up
+// to workers goroutines, and yields the results in the same order as seq. If
+// workers is not positive, [runtime.GOMAXPROCS] is used.
+//
+// Results are buffered until every earlier result has been yielded. To bound
+// memory, at most twice workers values are taken from seq ahead of the
+// consumer.
+//
+// An error from fn is yielded alongside its value and iteration continues. If
+// ctx is done first, its error is yielded once and iteration stops. Breaking
+// out of the loop cancels the context passed to fn. Either way, the iterator
+// waits for every goroutine it started to exit before returning.
+//
+//	for page, err := range syncx.ParallelMap(ctx, urls, 8, fetch) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(page)
+//	}
+func ParallelMap[T, R any](ctx context.Context, seq iter.Seq[T], workers int, fn func(ctx context.Context, v T) (R, error)) iter.Seq2[R, error] {
+	return func(yield func(R, error) bool) {
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+		type job struct {
+			i int
+			v T
+		}
+		type result struct {
+			i   int
+			r   R
+			err error
+		}
+		jobs := make(chan job)
+		results := make(chan result)
+		// window must be a buffered channel. Its length is the number of values
+		// taken from seq but not yet yielded.
+		window := make(chan struct{}, 2*workers)
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		// produce jobs. total is only set once seq is exhausted.
+		total := -1
+		wg.Go(func() {
+			defer close(jobs)
+			i := 0
+			for v := range seq {
+				select {
+				case <-ctx.Done():
+					return
+				case window <- struct{}{}:
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case jobs <- job{i, v}:
+				}
+				i++
+			}
+			total = i
+		})
+
+		// run jobs.
+		pool := wg.Child()
+		for range workers {
+			pool.Go(func() {
+				for j := range jobs {
+					r, err := fn(ctx, j.v)
+					select {
+					case <-ctx.Done():
+						return
+					case results <- result{j.i, r, err}:
+					}
+				}
+			})
+		}
+		wg.Go(func() {
+			pool.Wait()
+			close(results)
+		})
+
+		// yield results in order.
+		var zero R
+		pending := make(map[int]result)
+		next := 0
+		for {
+			select {
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			case res, ok := <-results:
+				if !ok {
+					// only cancellation stops jobs from finishing early.
+					if next != total {
+						yield(zero, ctx.Err())
+					}
+					return
+				}
+				pending[res.i] = res
+			}
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				<-window
+				if !yield(res.r, res.err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ForEach calls fn on each value of seq using up to workers goroutines. If
+// workers is not positive, [runtime.GOMAXPROCS] is used. It stops at the first
+// error, in the order of seq, and returns it, or returns ctx's error if it is
+// done first. Short for calling [ParallelMap].
+func ForEach[T any](ctx context.Context, seq iter.Seq[T], workers int, fn func(ctx context.Context, v T) error) error {
+	results := ParallelMap(ctx, seq, workers, func(ctx context.Context, v T) (struct{}, error) {
+		return struct{}{}, fn(ctx, v)
+	})
+	for _, err := range results {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
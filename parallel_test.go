@@ -0,0 +1,172 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// checkGoroutines fails t if goroutines started during the test are left
+// running once it finishes.
+func checkGoroutines(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		t.Helper()
+		// give exiting goroutines a moment to be reaped.
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Errorf("leaked %d goroutines", runtime.NumGoroutine()-before)
+	})
+}
+
+func TestParallelMap(t *testing.T) {
+	t.Run("preserves order", func(t *testing.T) {
+		checkGoroutines(t)
+		seq := slices.Values([]int{5, 4, 3, 2, 1, 0})
+		var got []int
+		for v, err := range ParallelMap(t.Context(), seq, 3, func(_ context.Context, v int) (int, error) {
+			time.Sleep(time.Duration(v) * time.Millisecond)
+			return v * 10, nil
+		}) {
+			if err != nil {
+				t.Fatalf("expected nil error, got %v", err)
+			}
+			got = append(got, v)
+		}
+		if !slices.Equal(got, []int{50, 40, 30, 20, 10, 0}) {
+			t.Fatalf("expected results in input order, got %v", got)
+		}
+	})
+	t.Run("limits workers", func(t *testing.T) {
+		checkGoroutines(t)
+		var running, peak atomic.Int64
+		seq := slices.Values(make([]int, 50))
+		for range ParallelMap(t.Context(), seq, 4, func(context.Context, int) (int, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return 0, nil
+		}) {
+		}
+		if p := peak.Load(); p > 4 {
+			t.Fatalf("expected at most 4 workers, got %d", p)
+		}
+	})
+	t.Run("yields errors in place", func(t *testing.T) {
+		checkGoroutines(t)
+		oops := errors.New("oops")
+		seq := slices.Values([]int{0, 1, 2})
+		var errs []error
+		for _, err := range ParallelMap(t.Context(), seq, 2, func(_ context.Context, v int) (int, error) {
+			if v == 1 {
+				return 0, oops
+			}
+			return v, nil
+		}) {
+			errs = append(errs, err)
+		}
+		if !slices.Equal(errs, []error{nil, oops, nil}) {
+			t.Fatalf("expected error at index 1, got %v", errs)
+		}
+	})
+	t.Run("break cancels and waits for workers", func(t *testing.T) {
+		checkGoroutines(t)
+		var running atomic.Int64
+		seq := func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		for v := range ParallelMap(t.Context(), seq, 4, func(ctx context.Context, v int) (int, error) {
+			running.Add(1)
+			defer running.Add(-1)
+			if v == 0 {
+				return v, nil
+			}
+			// blocks forever unless cancelled.
+			<-ctx.Done()
+			return v, ctx.Err()
+		}) {
+			if v == 0 {
+				break
+			}
+		}
+		if n := running.Load(); n != 0 {
+			t.Fatalf("expected every call to return before the loop exits, %d still running", n)
+		}
+	})
+	t.Run("yields context error", func(t *testing.T) {
+		checkGoroutines(t)
+		ctx, cancel := context.WithCancel(t.Context())
+		seq := slices.Values([]int{0, 1, 2})
+		var errs []error
+		for _, err := range ParallelMap(ctx, seq, 1, func(ctx context.Context, v int) (int, error) {
+			if v == 1 {
+				cancel()
+				<-ctx.Done()
+			}
+			return v, nil
+		}) {
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 || !errors.Is(errs[len(errs)-1], context.Canceled) {
+			t.Fatalf("expected iteration to end with context canceled, got %v", errs)
+		}
+	})
+	t.Run("empty sequence", func(t *testing.T) {
+		checkGoroutines(t)
+		for range ParallelMap(t.Context(), slices.Values([]int(nil)), 0, func(context.Context, int) (int, error) {
+			t.Fatal("fn should not be called")
+			return 0, nil
+		}) {
+			t.Fatal("expected no results")
+		}
+	})
+}
+
+func TestForEach(t *testing.T) {
+	t.Run("calls fn for each value", func(t *testing.T) {
+		checkGoroutines(t)
+		var sum atomic.Int64
+		err := ForEach(t.Context(), slices.Values([]int{1, 2, 3}), 2, func(_ context.Context, v int) error {
+			sum.Add(int64(v))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if n := sum.Load(); n != 6 {
+			t.Fatalf("expected sum 6, got %d", n)
+		}
+	})
+	t.Run("returns first error", func(t *testing.T) {
+		checkGoroutines(t)
+		oops := errors.New("oops")
+		err := ForEach(t.Context(), slices.Values([]int{1, 2, 3}), 2, func(_ context.Context, v int) error {
+			if v == 2 {
+				return oops
+			}
+			return nil
+		})
+		if err != oops {
+			t.Fatalf("expected %v, got %v", oops, err)
+		}
+	})
+}
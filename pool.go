@@ -0,0 +1,144 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ObjectPool is a typed wrapper around [sync.Pool]. It can optionally cap the
+// number of outstanding objects, in which case getting an object blocks until
+// one is put back. The zero value is an unbounded pool of zero values that is
+// safe to use. Fields must be set before first use and an ObjectPool must not
+// be copied after first use.
+//
+// Like [sync.Pool], storing non-pointer types allocates. Prefer pointer types
+// such as *bytes.Buffer.
+type ObjectPool[T any] struct {
+	// New optionally creates a value when the pool is empty. If nil, the zero
+	// value of T is used.
+	New func() T
+	// Reset optionally resets a value when it is put back in the pool.
+	Reset func(T)
+	// Max optionally caps the number of objects that have been gotten but not
+	// yet put back. Zero means unbounded.
+	Max int
+
+	pool sync.Pool
+	// slots must be a buffered channel with a capacity of Max. Its length is
+	// the number of outstanding objects. It is nil when unbounded.
+	slots       chan struct{}
+	once        sync.Once
+	hits        atomic.Int64
+	misses      atomic.Int64
+	outstanding atomic.Int64
+}
+
+// PoolStats is a snapshot of [ObjectPool] counters.
+type PoolStats struct {
+	// Hits is the number of gets served by a pooled object.
+	Hits int64
+	// Misses is the number of gets that had to create a new object.
+	Misses int64
+	// Outstanding is the number of objects gotten but not yet put back.
+	Outstanding int64
+}
+
+// Get gets an object from the pool, creating one if the pool is empty. When
+// bounded, Get blocks until an object is available. Short for calling
+// [ObjectPool.GetContext].
+func (p *ObjectPool[T]) Get() T {
+	v, _ := p.GetContext(context.Background())
+	return v
+}
+
+// GetContext gets an object from the pool, creating one if the pool is empty.
+// When bounded, it blocks until an object is available or returns ctx's error.
+func (p *ObjectPool[T]) GetContext(ctx context.Context) (T, error) {
+	if slots := p.state(); slots != nil {
+		select {
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		case slots <- struct{}{}:
+		}
+	}
+	return p.get(), nil
+}
+
+// TryGet gets an object from the pool and reports whether it succeeded. It only
+// fails when the pool is bounded and exhausted.
+func (p *ObjectPool[T]) TryGet() (T, bool) {
+	if slots := p.state(); slots != nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			var zero T
+			return zero, false
+		}
+	}
+	return p.get(), true
+}
+
+// get gets a pooled object or creates a new one. The caller must hold a slot
+// when bounded.
+func (p *ObjectPool[T]) get() T {
+	p.outstanding.Add(1)
+	if v, ok := p.pool.Get().(T); ok {
+		p.hits.Add(1)
+		return v
+	}
+	p.misses.Add(1)
+	if p.New == nil {
+		var zero T
+		return zero
+	}
+	return p.New()
+}
+
+// Put resets v and puts it back in the pool. When bounded, it panics if no
+// object is outstanding. When unbounded, putting objects that were never
+// gotten seeds the pool and leaves the outstanding count at zero.
+func (p *ObjectPool[T]) Put(v T) {
+	if slots := p.state(); slots != nil {
+		select {
+		case <-slots:
+		default:
+			panic("put to pool with no outstanding objects")
+		}
+	}
+	p.release()
+	if p.Reset != nil {
+		p.Reset(v)
+	}
+	p.pool.Put(v)
+}
+
+// release decrements the outstanding count unless it is zero.
+func (p *ObjectPool[T]) release() {
+	for {
+		n := p.outstanding.Load()
+		if n == 0 || p.outstanding.CompareAndSwap(n, n-1) {
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the pool's counters.
+func (p *ObjectPool[T]) Stats() PoolStats {
+	return PoolStats{
+		Hits:        p.hits.Load(),
+		Misses:      p.misses.Load(),
+		Outstanding: p.outstanding.Load(),
+	}
+}
+
+// state gets the raw slots chan. Initializes it if not done so yet.
+func (p *ObjectPool[T]) state() chan struct{} {
+	p.once.Do(func() {
+		if p.Max > 0 {
+			p.slots = make(chan struct{}, p.Max)
+		}
+	})
+	return p.slots
+}
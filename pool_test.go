@@ -0,0 +1,123 @@
+package syncx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestObjectPoolGet(t *testing.T) {
+	t.Run("zero value returns zero values", func(t *testing.T) {
+		var p ObjectPool[*bytes.Buffer]
+		if v := p.Get(); v != nil {
+			t.Fatal("expected zero value")
+		}
+		if s := p.Stats(); s.Misses != 1 || s.Outstanding != 1 {
+			t.Fatalf("unexpected stats %+v", s)
+		}
+	})
+	t.Run("calls new on miss", func(t *testing.T) {
+		p := ObjectPool[*bytes.Buffer]{New: func() *bytes.Buffer { return new(bytes.Buffer) }}
+		if v := p.Get(); v == nil {
+			t.Fatal("expected New to be called")
+		}
+	})
+	t.Run("reuses put objects", func(t *testing.T) {
+		p := ObjectPool[*bytes.Buffer]{
+			New:   func() *bytes.Buffer { return new(bytes.Buffer) },
+			Reset: (*bytes.Buffer).Reset,
+		}
+		v := p.Get()
+		v.WriteString("dirty")
+		p.Put(v)
+		if v.Len() != 0 {
+			t.Fatal("expected Reset to be called on Put")
+		}
+		// sync.Pool may drop objects at any time, so only check counters add
+		// up.
+		p.Get()
+		s := p.Stats()
+		if s.Hits+s.Misses != 2 || s.Outstanding != 1 {
+			t.Fatalf("unexpected stats %+v", s)
+		}
+	})
+}
+
+func TestObjectPoolMax(t *testing.T) {
+	t.Run("try get fails when exhausted", func(t *testing.T) {
+		p := ObjectPool[*int]{New: func() *int { return new(int) }, Max: 2}
+		a, _ := p.TryGet()
+		if _, ok := p.TryGet(); !ok {
+			t.Fatal("expected second TryGet to succeed")
+		}
+		if _, ok := p.TryGet(); ok {
+			t.Fatal("expected TryGet to fail when exhausted")
+		}
+		p.Put(a)
+		if _, ok := p.TryGet(); !ok {
+			t.Fatal("expected TryGet to succeed after Put")
+		}
+	})
+	t.Run("get context returns context error when exhausted", func(t *testing.T) {
+		p := ObjectPool[*int]{Max: 1}
+		p.Get()
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := p.GetContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+		if s := p.Stats(); s.Outstanding != 1 {
+			t.Fatalf("expected 1 outstanding, got %d", s.Outstanding)
+		}
+	})
+	t.Run("get blocks until put", func(t *testing.T) {
+		p := ObjectPool[*int]{New: func() *int { return new(int) }, Max: 1}
+		v := p.Get()
+		got := make(chan *int)
+		go func() {
+			got <- p.Get()
+		}()
+		p.Put(v)
+		<-got
+	})
+	t.Run("put panics when nothing is outstanding", func(t *testing.T) {
+		p := ObjectPool[*int]{Max: 1}
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		p.Put(new(int))
+	})
+	t.Run("put seeds an unbounded pool", func(t *testing.T) {
+		var p ObjectPool[*int]
+		seed := new(int)
+		p.Put(seed)
+		if s := p.Stats(); s.Outstanding != 0 {
+			t.Fatalf("expected 0 outstanding, got %d", s.Outstanding)
+		}
+		p.Get()
+		if s := p.Stats(); s.Outstanding != 1 {
+			t.Fatalf("expected 1 outstanding, got %d", s.Outstanding)
+		}
+	})
+}
+
+// must be tested with "-race"
+func TestObjectPool_race(t *testing.T) {
+	p := ObjectPool[*int]{New: func() *int { return new(int) }, Max: 4}
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			v := p.Get()
+			*v++
+			p.Put(v)
+		})
+	}
+	wg.Wait()
+	if s := p.Stats(); s.Outstanding != 0 || s.Hits+s.Misses != 100 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
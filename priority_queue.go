@@ -0,0 +1,353 @@
+package syncx
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Item indexes outside of the heap.
+const (
+	// itemPopped marks an item that was popped, removed or delivered.
+	itemPopped = -1
+	// itemOffered marks the item currently offered on Out.
+	itemOffered = -2
+)
+
+// PriorityQueue is a queue that always pops its highest priority item. Popping
+// is cancellable and can be used in select statements via
+// [PriorityQueue.Out]. Items can be re-prioritised or removed via the handle
+// returned when pushing them. Create one with [NewPriorityQueue]. A
+// PriorityQueue must not be copied after first use.
+//
+// Closing a queue rejects new items while the remaining ones are drained.
+type PriorityQueue[T any] struct {
+	// Max optionally caps the number of queued items, in which case pushing
+	// blocks while the queue is full. Zero means unbounded. Must be set before
+	// first use.
+	Max int
+
+	mu     Mutex
+	items  pqHeap[T]
+	closed bool
+	// changed is signalled whenever items or closed change.
+	changed notifier
+	outOnce sync.Once
+	out     chan T
+	// offer is the item the Out goroutine is trying to deliver. It is not in
+	// items. cancel is closed to make the Out goroutine take it back and done
+	// is closed once it has been delivered or taken back.
+	offer  *PriorityItem[T]
+	cancel chan struct{}
+	done   chan struct{}
+	// holds is the number of callers waiting on an offer to be taken back.
+	// Items are not offered while it is positive.
+	holds int
+}
+
+// PriorityItem is a handle to an item pushed to a [PriorityQueue].
+type PriorityItem[T any] struct {
+	v T
+	// index is the position in the heap, or one of itemPopped and itemOffered.
+	index int
+}
+
+// NewPriorityQueue returns an empty queue ordered by less, which reports
+// whether a has a higher priority than b.
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{items: pqHeap[T]{less: less}}
+}
+
+// Push adds v to pq, blocking while pq is full. Panics if pq is closed. Short
+// for calling [PriorityQueue.PushContext].
+func (pq *PriorityQueue[T]) Push(v T) *PriorityItem[T] {
+	it, err := pq.PushContext(context.Background(), v)
+	if err != nil {
+		panic("push to closed queue")
+	}
+	return it
+}
+
+// PushContext adds v to pq, blocking while pq is full. Returns [ErrClosed] if
+// pq is closed or ctx's error if it is done first.
+func (pq *PriorityQueue[T]) PushContext(ctx context.Context, v T) (*PriorityItem[T], error) {
+	for {
+		if err := pq.mu.LockContext(ctx); err != nil {
+			return nil, err
+		}
+		if pq.closed {
+			pq.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if pq.Max <= 0 || pq.len() < pq.Max {
+			it := &PriorityItem[T]{v: v}
+			heap.Push(&pq.items, it)
+			taken := pq.reoffer(it)
+			pq.changed.signal()
+			pq.mu.Unlock()
+			// once Push returns, Out must not deliver the worse item.
+			if taken != nil {
+				<-taken
+			}
+			return it, nil
+		}
+		ch := pq.changed.wait()
+		pq.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// PopContext removes and returns the highest priority item, blocking while pq
+// is empty. Returns [ErrClosed] once pq is closed and drained, or ctx's error
+// if it is done first.
+func (pq *PriorityQueue[T]) PopContext(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		if err := pq.mu.LockContext(ctx); err != nil {
+			return zero, err
+		}
+		if it, ok := pq.pop(); ok {
+			pq.mu.Unlock()
+			return it.v, nil
+		}
+		if pq.closed && pq.offer == nil {
+			pq.mu.Unlock()
+			return zero, ErrClosed
+		}
+		ch := pq.changed.wait()
+		pq.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// TryPop removes and returns the highest priority item and reports whether pq
+// had one.
+func (pq *PriorityQueue[T]) TryPop() (T, bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	it, ok := pq.pop()
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return it.v, true
+}
+
+// Out returns a channel that receives the highest priority item. It is closed
+// once pq is closed and drained. Do not close it.
+//
+// The first call starts a goroutine that offers the best item to receivers. If
+// a better item is pushed, or the offered item is updated or removed, the
+// offer is taken back and the best item is chosen again before Push or Update
+// returns, so Out only delivers a worse item to a receiver that raced with
+// them. Mixing Out with
+// [PriorityQueue.PopContext] pops items from the rest of the queue while one
+// is being offered.
+func (pq *PriorityQueue[T]) Out() <-chan T {
+	pq.outOnce.Do(func() {
+		pq.out = make(chan T)
+		go func() {
+			defer close(pq.out)
+			for {
+				it, v, cancel, ok := pq.takeOffer()
+				if !ok {
+					return
+				}
+				// a select with a waiting receiver picks at random, so don't
+				// deliver an offer that was already taken back.
+				select {
+				case <-cancel:
+					pq.finishOffer(it, false)
+					continue
+				default:
+				}
+				select {
+				case pq.out <- v:
+					pq.finishOffer(it, true)
+				case <-cancel:
+					pq.finishOffer(it, false)
+				}
+			}
+		}()
+	})
+	return pq.out
+}
+
+// Update changes the value, and therefore the priority, of it. Reports false if
+// it was already popped or removed.
+func (pq *PriorityQueue[T]) Update(it *PriorityItem[T], v T) bool {
+	pq.mu.Lock()
+	if !pq.reclaim(it) {
+		pq.mu.Unlock()
+		return false
+	}
+	it.v = v
+	heap.Fix(&pq.items, it.index)
+	taken := pq.reoffer(it)
+	pq.changed.signal()
+	pq.mu.Unlock()
+	if taken != nil {
+		<-taken
+	}
+	return true
+}
+
+// Remove removes it from pq. Reports false if it was already popped or
+// removed.
+func (pq *PriorityQueue[T]) Remove(it *PriorityItem[T]) bool {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	if !pq.reclaim(it) {
+		return false
+	}
+	heap.Remove(&pq.items, it.index)
+	it.index = itemPopped
+	pq.changed.signal()
+	return true
+}
+
+// Len returns the number of queued items, including one being offered on Out.
+func (pq *PriorityQueue[T]) Len() int {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	return pq.len()
+}
+
+// Close stops pq from accepting new items. Items already queued can still be
+// popped. Calling Close more than once is a no-op.
+func (pq *PriorityQueue[T]) Close() {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	pq.closed = true
+	pq.changed.signal()
+}
+
+// len counts queued items. Must hold mu.
+func (pq *PriorityQueue[T]) len() int {
+	n := pq.items.Len()
+	if pq.offer != nil {
+		n++
+	}
+	return n
+}
+
+// pop removes the highest priority item from the heap. Must hold mu.
+func (pq *PriorityQueue[T]) pop() (*PriorityItem[T], bool) {
+	if pq.items.Len() == 0 {
+		return nil, false
+	}
+	it := heap.Pop(&pq.items).(*PriorityItem[T])
+	it.index = itemPopped
+	pq.changed.signal()
+	return it, true
+}
+
+// reclaim makes sure it is in the heap, taking it back from the Out goroutine
+// if it is being offered. Reports false if it was popped. Must hold mu, which
+// may be released while waiting.
+func (pq *PriorityQueue[T]) reclaim(it *PriorityItem[T]) bool {
+	for it.index == itemOffered {
+		pq.cancelOffer()
+		done := pq.done
+		pq.holds++
+		pq.mu.Unlock()
+		<-done
+		pq.mu.Lock()
+		pq.holds--
+		pq.changed.signal()
+	}
+	return it.index != itemPopped
+}
+
+// reoffer takes back the current offer if it is no longer the best item. It
+// returns a channel that is closed once the offer has been taken back or
+// delivered, or nil if the offer is still the best. Must hold mu.
+func (pq *PriorityQueue[T]) reoffer(it *PriorityItem[T]) <-chan struct{} {
+	if pq.offer == nil || !pq.items.less(it.v, pq.offer.v) {
+		return nil
+	}
+	pq.cancelOffer()
+	return pq.done
+}
+
+// cancelOffer asks the Out goroutine to take back its offer. Must hold mu.
+func (pq *PriorityQueue[T]) cancelOffer() {
+	if pq.cancel != nil {
+		close(pq.cancel)
+		pq.cancel = nil
+	}
+}
+
+// takeOffer blocks until there is an item to offer and marks it as offered. It
+// reports false once pq is closed and drained.
+func (pq *PriorityQueue[T]) takeOffer() (it *PriorityItem[T], v T, cancel <-chan struct{}, ok bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	for pq.holds > 0 || pq.items.Len() == 0 {
+		if pq.holds == 0 && pq.closed {
+			return nil, v, nil, false
+		}
+		ch := pq.changed.wait()
+		pq.mu.Unlock()
+		<-ch
+		pq.mu.Lock()
+	}
+	it = heap.Pop(&pq.items).(*PriorityItem[T])
+	it.index = itemOffered
+	pq.offer = it
+	pq.cancel = make(chan struct{})
+	pq.done = make(chan struct{})
+	return it, it.v, pq.cancel, true
+}
+
+// finishOffer records whether it was delivered, putting it back in the heap if
+// not.
+func (pq *PriorityQueue[T]) finishOffer(it *PriorityItem[T], delivered bool) {
+	pq.mu.Lock()
+	defer pq.mu.Unlock()
+	if delivered {
+		it.index = itemPopped
+	} else {
+		heap.Push(&pq.items, it)
+	}
+	close(pq.done)
+	pq.offer, pq.cancel, pq.done = nil, nil, nil
+	pq.changed.signal()
+}
+
+// pqHeap implements [heap.Interface] for [PriorityItem] handles.
+type pqHeap[T any] struct {
+	items []*PriorityItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *pqHeap[T]) Len() int           { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool { return h.less(h.items[i].v, h.items[j].v) }
+
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *pqHeap[T]) Push(x any) {
+	it := x.(*PriorityItem[T])
+	it.index = len(h.items)
+	h.items = append(h.items, it)
+}
+
+func (h *pqHeap[T]) Pop() any {
+	n := len(h.items) - 1
+	it := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	return it
+}
@@ -0,0 +1,231 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+func TestPriorityQueuePushPop(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	for _, v := range []int{3, 1, 2} {
+		pq.Push(v)
+	}
+	if n := pq.Len(); n != 3 {
+		t.Fatalf("expected 3 items, got %d", n)
+	}
+	for want := 1; want <= 3; want++ {
+		v, err := pq.PopContext(t.Context())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if v != want {
+			t.Fatalf("expected %d, got %d", want, v)
+		}
+	}
+	if _, ok := pq.TryPop(); ok {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestPriorityQueuePopContext(t *testing.T) {
+	t.Run("blocks until push", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		go func() {
+			time.Sleep(time.Millisecond)
+			pq.Push(1)
+		}()
+		if v, err := pq.PopContext(t.Context()); err != nil || v != 1 {
+			t.Fatalf("expected 1, nil, got %d, %v", v, err)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := pq.PopContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestPriorityQueueUpdate(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	pq.Push(1)
+	it := pq.Push(5)
+	if !pq.Update(it, 0) {
+		t.Fatal("expected Update to succeed")
+	}
+	if v, _ := pq.TryPop(); v != 0 {
+		t.Fatalf("expected updated item first, got %d", v)
+	}
+	if pq.Update(it, 10) {
+		t.Fatal("expected Update of popped item to fail")
+	}
+}
+
+func TestPriorityQueueRemove(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	it := pq.Push(1)
+	pq.Push(2)
+	if !pq.Remove(it) {
+		t.Fatal("expected Remove to succeed")
+	}
+	if pq.Remove(it) {
+		t.Fatal("expected second Remove to fail")
+	}
+	if v, _ := pq.TryPop(); v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+}
+
+func TestPriorityQueueClose(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	pq.Push(1)
+	pq.Close()
+	if _, err := pq.PushContext(t.Context(), 2); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if v, err := pq.PopContext(t.Context()); err != nil || v != 1 {
+		t.Fatalf("expected 1, nil, got %d, %v", v, err)
+	}
+	if _, err := pq.PopContext(t.Context()); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestPriorityQueueMax(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	pq.Max = 1
+	pq.Push(1)
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+	defer cancel()
+	if _, err := pq.PushContext(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPriorityQueueOut(t *testing.T) {
+	t.Run("delivers in priority order", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		for _, v := range []int{3, 1, 2} {
+			pq.Push(v)
+		}
+		pq.Close()
+		want := 1
+		for v := range pq.Out() {
+			if v != want {
+				t.Fatalf("expected %d, got %d", want, v)
+			}
+			want++
+		}
+		if want != 4 {
+			t.Fatalf("expected 3 items, got %d", want-1)
+		}
+	})
+	t.Run("offers a better item pushed later", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		pq.Push(2)
+		out := pq.Out()
+		waitOffered(t, pq)
+		pq.Push(1)
+		if v := <-out; v != 1 {
+			t.Fatalf("expected 1, got %d", v)
+		}
+	})
+	t.Run("updates the offered item", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		it := pq.Push(1)
+		pq.Push(2)
+		out := pq.Out()
+		waitOffered(t, pq)
+		if !pq.Update(it, 3) {
+			t.Fatal("expected Update of offered item to succeed")
+		}
+		if v := <-out; v != 2 {
+			t.Fatalf("expected 2, got %d", v)
+		}
+	})
+	t.Run("removes the offered item", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		it := pq.Push(1)
+		pq.Push(2)
+		out := pq.Out()
+		waitOffered(t, pq)
+		if !pq.Remove(it) {
+			t.Fatal("expected Remove of offered item to succeed")
+		}
+		if v := <-out; v != 2 {
+			t.Fatalf("expected 2, got %d", v)
+		}
+	})
+	t.Run("cannot remove a delivered item", func(t *testing.T) {
+		pq := NewPriorityQueue(intLess)
+		it := pq.Push(1)
+		<-pq.Out()
+		if pq.Remove(it) {
+			t.Fatal("expected Remove of delivered item to fail")
+		}
+	})
+}
+
+// waitOffered waits until the Out goroutine is offering an item.
+func waitOffered[T any](t *testing.T, pq *PriorityQueue[T]) {
+	t.Helper()
+	for {
+		pq.mu.Lock()
+		offered := pq.offer != nil
+		pq.mu.Unlock()
+		if offered {
+			return
+		}
+		time.Sleep(time.Microsecond)
+	}
+}
+
+// must be tested with "-race"
+func TestPriorityQueue_race(t *testing.T) {
+	pq := NewPriorityQueue(intLess)
+	n := 100
+	var producers sync.WaitGroup
+	for i := range n {
+		producers.Go(func() {
+			it := pq.Push(i)
+			pq.Update(it, i)
+		})
+	}
+	got := make(chan int, n)
+	var consumers sync.WaitGroup
+	consumers.Go(func() {
+		for v := range pq.Out() {
+			got <- v
+		}
+	})
+	consumers.Go(func() {
+		for {
+			v, err := pq.PopContext(t.Context())
+			if err != nil {
+				return
+			}
+			got <- v
+		}
+	})
+	producers.Wait()
+	pq.Close()
+	consumers.Wait()
+	close(got)
+	seen := make(map[int]bool)
+	for v := range got {
+		if seen[v] {
+			t.Fatalf("item %d popped twice", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d items, got %d", n, len(seen))
+	}
+}
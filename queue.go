@@ -0,0 +1,158 @@
+package syncx
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue is a FIFO queue of items that is unbounded unless Max is set. Popping
+// is cancellable and can be used in select statements via [Queue.Out]. The
+// zero value is an empty unbounded queue that is safe to use. A Queue must not
+// be copied after first use.
+//
+// Closing a queue rejects new items while the remaining ones are drained.
+type Queue[T any] struct {
+	// Max optionally caps the number of queued items, in which case pushing
+	// blocks while the queue is full. Zero means unbounded. Must be set before
+	// first use.
+	Max int
+
+	mu     Mutex
+	items  []T
+	closed bool
+	// changed is signalled whenever items or closed change.
+	changed notifier
+	outOnce sync.Once
+	out     chan T
+}
+
+// Push adds v to the back of q, blocking while q is full. Panics if q is
+// closed. Short for calling [Queue.PushContext].
+func (q *Queue[T]) Push(v T) {
+	if err := q.PushContext(context.Background(), v); err != nil {
+		panic("push to closed queue")
+	}
+}
+
+// PushContext adds v to the back of q, blocking while q is full. Returns
+// [ErrClosed] if q is closed or ctx's error if it is done first.
+func (q *Queue[T]) PushContext(ctx context.Context, v T) error {
+	for {
+		if err := q.mu.LockContext(ctx); err != nil {
+			return err
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return ErrClosed
+		}
+		if q.Max <= 0 || len(q.items) < q.Max {
+			q.items = append(q.items, v)
+			q.changed.signal()
+			q.mu.Unlock()
+			return nil
+		}
+		ch := q.changed.wait()
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// PopContext removes and returns the item at the front of q, blocking while q
+// is empty. Returns [ErrClosed] once q is closed and drained, or ctx's error if
+// it is done first.
+func (q *Queue[T]) PopContext(ctx context.Context) (T, error) {
+	var zero T
+	for {
+		if err := q.mu.LockContext(ctx); err != nil {
+			return zero, err
+		}
+		if v, ok := q.pop(); ok {
+			q.mu.Unlock()
+			return v, nil
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return zero, ErrClosed
+		}
+		ch := q.changed.wait()
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// TryPop removes and returns the item at the front of q and reports whether q
+// had one.
+func (q *Queue[T]) TryPop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pop()
+}
+
+// Out returns a channel that receives items from the front of q. It is closed
+// once q is closed and drained. Do not close it.
+//
+// The first call starts a goroutine that forwards items, so one popped item
+// may be held waiting for a receiver and will not be counted by [Queue.Len].
+// The goroutine exits once q is closed and drained.
+//
+//	select {
+//	case job, ok := <-q.Out():
+//	case <-ctx.Done():
+//	}
+func (q *Queue[T]) Out() <-chan T {
+	q.outOnce.Do(func() {
+		q.out = make(chan T)
+		go forward(q.out, q.PopContext)
+	})
+	return q.out
+}
+
+// Len returns the number of queued items.
+func (q *Queue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Close stops q from accepting new items. Items already queued can still be
+// popped. Calling Close more than once is a no-op.
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.changed.signal()
+}
+
+// pop removes the front item. Must hold mu.
+func (q *Queue[T]) pop() (T, bool) {
+	var zero T
+	if len(q.items) == 0 {
+		return zero, false
+	}
+	v := q.items[0]
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.changed.signal()
+	return v, true
+}
+
+// forward sends the items returned by pop to out until pop fails, then closes
+// out. It backs the Out channels of the queues.
+func forward[T any](out chan<- T, pop func(ctx context.Context) (T, error)) {
+	defer close(out)
+	for {
+		v, err := pop(context.Background())
+		if err != nil {
+			return
+		}
+		out <- v
+	}
+}
@@ -0,0 +1,173 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueuePushPop(t *testing.T) {
+	var q Queue[int]
+	for i := range 3 {
+		q.Push(i)
+	}
+	if n := q.Len(); n != 3 {
+		t.Fatalf("expected 3 items, got %d", n)
+	}
+	for i := range 3 {
+		v, err := q.PopContext(t.Context())
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+	}
+	if _, ok := q.TryPop(); ok {
+		t.Fatal("expected empty queue")
+	}
+}
+
+func TestQueuePopContext(t *testing.T) {
+	t.Run("blocks until push", func(t *testing.T) {
+		var q Queue[int]
+		go func() {
+			time.Sleep(time.Millisecond)
+			q.Push(1)
+		}()
+		if v, err := q.PopContext(t.Context()); err != nil || v != 1 {
+			t.Fatalf("expected 1, nil, got %d, %v", v, err)
+		}
+	})
+	t.Run("returns context error", func(t *testing.T) {
+		var q Queue[int]
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := q.PopContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestQueueClose(t *testing.T) {
+	t.Run("drains remaining items", func(t *testing.T) {
+		var q Queue[int]
+		q.Push(1)
+		q.Close()
+		q.Close()
+		if v, err := q.PopContext(t.Context()); err != nil || v != 1 {
+			t.Fatalf("expected 1, nil, got %d, %v", v, err)
+		}
+		if _, err := q.PopContext(t.Context()); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	})
+	t.Run("wakes blocked pop", func(t *testing.T) {
+		var q Queue[int]
+		go func() {
+			time.Sleep(time.Millisecond)
+			q.Close()
+		}()
+		if _, err := q.PopContext(t.Context()); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	})
+	t.Run("rejects push", func(t *testing.T) {
+		var q Queue[int]
+		q.Close()
+		if err := q.PushContext(t.Context(), 1); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected Push to panic")
+			}
+		}()
+		q.Push(1)
+	})
+}
+
+func TestQueueMax(t *testing.T) {
+	t.Run("push context blocks while full", func(t *testing.T) {
+		q := Queue[int]{Max: 1}
+		q.Push(1)
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		if err := q.PushContext(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	})
+	t.Run("push resumes after pop", func(t *testing.T) {
+		q := Queue[int]{Max: 1}
+		q.Push(1)
+		go func() {
+			time.Sleep(time.Millisecond)
+			q.TryPop()
+		}()
+		if err := q.PushContext(t.Context(), 2); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if v, _ := q.TryPop(); v != 2 {
+			t.Fatalf("expected 2, got %d", v)
+		}
+	})
+}
+
+func TestQueueOut(t *testing.T) {
+	var q Queue[int]
+	for i := range 3 {
+		q.Push(i)
+	}
+	q.Close()
+	i := 0
+	for v := range q.Out() {
+		if v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+		i++
+	}
+	if i != 3 {
+		t.Fatalf("expected 3 items, got %d", i)
+	}
+}
+
+// must be tested with "-race"
+func TestQueue_race(t *testing.T) {
+	q := Queue[int]{Max: 4}
+	n := 100
+	var producers sync.WaitGroup
+	for i := range n {
+		producers.Go(func() {
+			q.Push(i)
+		})
+	}
+	got := make(chan int, n)
+	var consumers sync.WaitGroup
+	for range 4 {
+		consumers.Go(func() {
+			for {
+				v, err := q.PopContext(t.Context())
+				if err != nil {
+					return
+				}
+				got <- v
+			}
+		})
+	}
+	producers.Wait()
+	q.Close()
+	consumers.Wait()
+	close(got)
+	seen := make(map[int]bool)
+	for v := range got {
+		if seen[v] {
+			t.Fatalf("item %d popped twice", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != n {
+		t.Fatalf("expected %d items, got %d", n, len(seen))
+	}
+}
@@ -0,0 +1,120 @@
+package syncx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// Scope is a structured concurrency scope created by [Run]. Goroutines started
+// with [Scope.Go] can not outlive the call to Run that created the scope.
+type Scope struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     WaitGroup
+	mu     Mutex
+	// err is the first error returned.
+	err error
+	// panicked is the first panic recovered.
+	panicked *PanicError
+}
+
+// PanicError is a panic recovered from a goroutine in a [Scope]. [Run]
+// re-panics with it in the calling goroutine.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\ngoroutine stack:\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns Value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Run calls f with a new [Scope] and waits for every goroutine started in it
+// to exit, even after f returns. The first error returned by f or by any
+// goroutine cancels the scope's context and is returned by Run. If f or a
+// goroutine panics, the scope is cancelled and Run panics with a
+// [*PanicError] once everything has exited.
+//
+// Scopes nest by calling Run with the context of the enclosing scope.
+//
+//	err := syncx.Run(ctx, func(s *syncx.Scope) error {
+//	    s.Go(func(ctx context.Context) error {
+//	        return consume(ctx, jobs)
+//	    })
+//	    return produce(s.Context(), jobs)
+//	})
+func Run(ctx context.Context, f func(s *Scope) error) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	s := &Scope{ctx: ctx, cancel: cancel}
+	s.do(func() error {
+		return f(s)
+	})
+	s.wg.Wait()
+	// Go may race with the counter reaching zero, so wait for anything that
+	// got in before closing.
+	s.wg.Close()
+	s.wg.Wait()
+	cancel(context.Canceled)
+	if s.panicked != nil {
+		panic(s.panicked)
+	}
+	return s.err
+}
+
+// Context returns the scope's context, which is cancelled on the first error
+// or panic, or once [Run] returns.
+func (s *Scope) Context() context.Context {
+	return s.ctx
+}
+
+// Go runs f in a new goroutine that [Run] waits for. f receives the scope's
+// context. Go may be called from goroutines in the scope. Panics if called
+// after Run has returned.
+func (s *Scope) Go(f func(ctx context.Context) error) {
+	err := s.wg.TryGo(func() {
+		s.do(func() error {
+			return f(s.ctx)
+		})
+	})
+	if err != nil {
+		panic("go on closed Scope")
+	}
+}
+
+// do calls f, recording an error or panic and cancelling the scope on either.
+func (s *Scope) do(f func() error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		// a nested scope already captured the stack.
+		pe, ok := v.(*PanicError)
+		if !ok {
+			pe = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.panicked == nil {
+			s.panicked = pe
+		}
+		s.cancel(pe)
+	}()
+	if err := f(); err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.err == nil {
+			s.err = err
+		}
+		s.cancel(err)
+	}
+}
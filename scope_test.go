@@ -0,0 +1,176 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("waits for every goroutine", func(t *testing.T) {
+		var finished atomic.Int64
+		err := Run(t.Context(), func(s *Scope) error {
+			for range 10 {
+				s.Go(func(context.Context) error {
+					time.Sleep(time.Millisecond)
+					finished.Add(1)
+					return nil
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if n := finished.Load(); n != 10 {
+			t.Fatalf("expected 10 goroutines to finish before Run returns, got %d", n)
+		}
+	})
+	t.Run("first error cancels the scope", func(t *testing.T) {
+		oops := errors.New("oops")
+		err := Run(t.Context(), func(s *Scope) error {
+			s.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				if cause := context.Cause(ctx); cause != oops {
+					t.Errorf("expected cause %v, got %v", oops, cause)
+				}
+				return ctx.Err()
+			})
+			s.Go(func(context.Context) error {
+				return oops
+			})
+			return nil
+		})
+		if err != oops {
+			t.Fatalf("expected %v, got %v", oops, err)
+		}
+	})
+	t.Run("returns error of f", func(t *testing.T) {
+		oops := errors.New("oops")
+		if err := Run(t.Context(), func(*Scope) error { return oops }); err != oops {
+			t.Fatalf("expected %v, got %v", oops, err)
+		}
+	})
+	t.Run("children may start siblings", func(t *testing.T) {
+		var finished atomic.Bool
+		Run(t.Context(), func(s *Scope) error {
+			s.Go(func(context.Context) error {
+				time.Sleep(time.Millisecond)
+				s.Go(func(context.Context) error {
+					time.Sleep(time.Millisecond)
+					finished.Store(true)
+					return nil
+				})
+				return nil
+			})
+			return nil
+		})
+		if !finished.Load() {
+			t.Fatal("expected sibling to finish before Run returns")
+		}
+	})
+	t.Run("context is cancelled once Run returns", func(t *testing.T) {
+		var ctx context.Context
+		Run(t.Context(), func(s *Scope) error {
+			ctx = s.Context()
+			return nil
+		})
+		if ctx.Err() == nil {
+			t.Fatal("expected scope context to be cancelled")
+		}
+	})
+	t.Run("parent cancellation propagates", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		err := Run(ctx, func(s *Scope) error {
+			s.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			cancel()
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestRun_panics(t *testing.T) {
+	t.Run("child panic propagates", func(t *testing.T) {
+		var cancelled atomic.Bool
+		defer func() {
+			pe, ok := recover().(*PanicError)
+			if !ok {
+				t.Fatal("expected Run to panic with *PanicError")
+			}
+			if pe.Value != "boom" {
+				t.Fatalf("expected panic value boom, got %v", pe.Value)
+			}
+			if len(pe.Stack) == 0 {
+				t.Fatal("expected stack to be captured")
+			}
+			if !cancelled.Load() {
+				t.Fatal("expected siblings to be cancelled and waited for")
+			}
+		}()
+		Run(t.Context(), func(s *Scope) error {
+			s.Go(func(ctx context.Context) error {
+				<-ctx.Done()
+				cancelled.Store(true)
+				return nil
+			})
+			s.Go(func(context.Context) error {
+				panic("boom")
+			})
+			return nil
+		})
+	})
+	t.Run("nested panic propagates once wrapped", func(t *testing.T) {
+		defer func() {
+			pe, ok := recover().(*PanicError)
+			if !ok {
+				t.Fatal("expected Run to panic with *PanicError")
+			}
+			if pe.Value != "boom" {
+				t.Fatalf("expected inner panic value, got %v", pe.Value)
+			}
+		}()
+		Run(t.Context(), func(s *Scope) error {
+			s.Go(func(ctx context.Context) error {
+				return Run(ctx, func(inner *Scope) error {
+					inner.Go(func(context.Context) error {
+						panic("boom")
+					})
+					return nil
+				})
+			})
+			return nil
+		})
+	})
+	t.Run("go after close panics", func(t *testing.T) {
+		var leaked *Scope
+		Run(t.Context(), func(s *Scope) error {
+			leaked = s
+			return nil
+		})
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected Go on a closed scope to panic")
+			}
+		}()
+		leaked.Go(func(context.Context) error { return nil })
+	})
+}
+
+func TestPanicError(t *testing.T) {
+	oops := errors.New("oops")
+	err := error(&PanicError{Value: oops})
+	if !errors.Is(err, oops) {
+		t.Fatal("expected PanicError to unwrap an error value")
+	}
+	if errors.Unwrap(&PanicError{Value: "oops"}) != nil {
+		t.Fatal("expected PanicError not to unwrap a non-error value")
+	}
+}
@@ -0,0 +1,263 @@
+package syncx
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Shutdown coordinates a graceful shutdown. Components register hooks in
+// phases; once shutdown is triggered, by a signal or by calling
+// [Shutdown.Trigger], phases run in ascending order and the hooks of a phase
+// run in parallel.
+//
+// The zero value is ready to use. Fields must be set before first use. A
+// Shutdown must not be copied after first use.
+//
+//	var sd syncx.Shutdown
+//	defer sd.Notify()()
+//	sd.Register("http", 0, 10*time.Second, server.Shutdown)
+//	sd.Register("workers", 1, 0, wg.WaitContext)
+//	<-sd.Context().Done()
+//	if err := sd.Wait(ctx); err != nil {
+//	    log.Print(err)
+//	}
+type Shutdown struct {
+	// Timeout is the timeout of hooks registered without one. Zero means no
+	// timeout.
+	Timeout time.Duration
+
+	mu      Mutex
+	hooks   []shutdownHook
+	started bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	err     error
+}
+
+type shutdownHook struct {
+	name    string
+	phase   int
+	timeout time.Duration
+	f       func(ctx context.Context) error
+}
+
+// HookError is a shutdown hook that failed or timed out.
+type HookError struct {
+	// Name is the name the hook was registered with.
+	Name string
+	// Phase is the phase the hook ran in.
+	Phase int
+	// Err is the error returned by the hook, [context.DeadlineExceeded] if it
+	// timed out, or a [*PanicError] if it panicked.
+	Err error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("phase %d: %s: %v", e.Phase, e.Name, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownError reports the hooks that failed or timed out during a shutdown.
+type ShutdownError struct {
+	// Hooks are ordered by phase, then by registration.
+	Hooks []*HookError
+}
+
+func (e *ShutdownError) Error() string {
+	var b strings.Builder
+	b.WriteString("shutdown:")
+	for _, h := range e.Hooks {
+		b.WriteString("\n\t")
+		b.WriteString(h.Error())
+	}
+	return b.String()
+}
+
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Hooks))
+	for i, h := range e.Hooks {
+		errs[i] = h
+	}
+	return errs
+}
+
+// Register adds a hook named name that runs f in the given phase. f receives a
+// context that expires after timeout, or after the Timeout field if timeout is
+// zero. A hook that does not return in time is reported as timed out and
+// left running. Returns [ErrClosed] if shutdown has started.
+func (s *Shutdown) Register(name string, phase int, timeout time.Duration, f func(ctx context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrClosed
+	}
+	if timeout == 0 {
+		timeout = s.Timeout
+	}
+	s.hooks = append(s.hooks, shutdownHook{name: name, phase: phase, timeout: timeout, f: f})
+	return nil
+}
+
+// Trigger starts the shutdown if it hasn't started yet, and returns without
+// waiting for it.
+func (s *Shutdown) Trigger() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.init()
+	s.started = true
+	s.cancel()
+	hooks := slices.Clone(s.hooks)
+	go s.run(hooks)
+}
+
+// Notify triggers the shutdown when one of the given signals arrives, or
+// os.Interrupt or SIGTERM if none are given. Once a signal arrives it is no
+// longer relayed, so a second one terminates the process as usual. The
+// returned func stops listening.
+func (s *Shutdown) Notify(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+	stopped := make(chan struct{})
+	go func() {
+		defer signal.Stop(ch)
+		select {
+		case <-ch:
+			s.Trigger()
+		case <-stopped:
+		}
+	}()
+	return sync.OnceFunc(func() {
+		close(stopped)
+	})
+}
+
+// Context returns a context that is cancelled when shutdown starts.
+func (s *Shutdown) Context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	return s.ctx
+}
+
+// Done returns a channel that is closed once every phase has finished.
+func (s *Shutdown) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.init()
+	return s.done
+}
+
+// Wait waits for shutdown to finish, or for ctx to be done. Returns a
+// [*ShutdownError] if any hook failed or timed out, or the context's error if
+// ctx is done first.
+func (s *Shutdown) Wait(ctx context.Context) error {
+	select {
+	case <-s.Done():
+		return s.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err returns a [*ShutdownError] if shutdown has finished and any hook failed
+// or timed out, or nil otherwise.
+func (s *Shutdown) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// init creates the context and done channel. Must hold mu.
+func (s *Shutdown) init() {
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+		s.done = make(chan struct{})
+	}
+}
+
+// run runs hooks phase by phase.
+func (s *Shutdown) run(hooks []shutdownHook) {
+	slices.SortStableFunc(hooks, func(a, b shutdownHook) int {
+		return cmp.Compare(a.phase, b.phase)
+	})
+	var failed []*HookError
+	for len(hooks) > 0 {
+		n := 1
+		for n < len(hooks) && hooks[n].phase == hooks[0].phase {
+			n++
+		}
+		failed = append(failed, runPhase(hooks[:n])...)
+		hooks = hooks[n:]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(failed) > 0 {
+		s.err = &ShutdownError{Hooks: failed}
+	}
+	close(s.done)
+}
+
+// runPhase runs hooks in parallel and returns those that failed in the order
+// they were given.
+func runPhase(hooks []shutdownHook) []*HookError {
+	errs := make([]error, len(hooks))
+	var wg WaitGroup
+	for i, h := range hooks {
+		wg.Go(func() {
+			errs[i] = runHook(h)
+		})
+	}
+	wg.Wait()
+	var failed []*HookError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &HookError{Name: hooks[i].name, Phase: hooks[i].phase, Err: err})
+		}
+	}
+	return failed
+}
+
+// runHook runs h, giving up on it once its timeout has elapsed. A panic is
+// returned as a [*PanicError].
+func runHook(h shutdownHook) error {
+	ctx := context.Background()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				result <- &PanicError{Value: v, Stack: debug.Stack()}
+			}
+		}()
+		result <- h.f(ctx)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,147 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("runs phases in order", func(t *testing.T) {
+		var sd Shutdown
+		ran := make(chan string, 10)
+		release := make(chan struct{})
+		hook := func(name string) func(context.Context) error {
+			return func(context.Context) error {
+				ran <- name
+				<-release
+				return nil
+			}
+		}
+		sd.Register("b", 1, 0, hook("b"))
+		sd.Register("a1", 0, 0, hook("a1"))
+		sd.Register("a2", 0, 0, hook("a2"))
+		sd.Trigger()
+		// both hooks of phase 0 must be running at once.
+		got := []string{<-ran, <-ran}
+		slices.Sort(got)
+		if !slices.Equal(got, []string{"a1", "a2"}) {
+			t.Fatalf("expected phase 0 to run first, got %v", got)
+		}
+		select {
+		case name := <-ran:
+			t.Fatalf("expected %s to wait for phase 0", name)
+		case <-time.After(5 * time.Millisecond):
+		}
+		close(release)
+		if name := <-ran; name != "b" {
+			t.Fatalf("expected b to run last, got %s", name)
+		}
+		if err := sd.Wait(t.Context()); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+	t.Run("reports failed and timed out hooks", func(t *testing.T) {
+		sd := Shutdown{Timeout: time.Millisecond}
+		oops := errors.New("oops")
+		release := make(chan struct{})
+		defer close(release)
+		sd.Register("ok", 0, 0, func(context.Context) error { return nil })
+		sd.Register("fails", 0, 0, func(context.Context) error { return oops })
+		sd.Register("stuck", 1, 0, func(context.Context) error {
+			<-release
+			return nil
+		})
+		sd.Trigger()
+		err := sd.Wait(t.Context())
+		var se *ShutdownError
+		if !errors.As(err, &se) {
+			t.Fatalf("expected *ShutdownError, got %v", err)
+		}
+		want := []HookError{
+			{Name: "fails", Phase: 0, Err: oops},
+			{Name: "stuck", Phase: 1, Err: context.DeadlineExceeded},
+		}
+		if !slices.EqualFunc(se.Hooks, want, func(a *HookError, b HookError) bool { return *a == b }) {
+			t.Fatalf("expected %v, got %v", want, se.Hooks)
+		}
+		var he *HookError
+		if !errors.As(err, &he) || he.Name != "fails" {
+			t.Fatalf("expected errors.As to find the first *HookError, got %v", he)
+		}
+		if !errors.Is(err, oops) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatal("expected error to wrap the hook errors")
+		}
+	})
+	t.Run("reports panicking hooks and runs later phases", func(t *testing.T) {
+		var sd Shutdown
+		ran := false
+		sd.Register("panics", 0, 0, func(context.Context) error {
+			panic("boom")
+		})
+		sd.Register("later", 1, 0, func(context.Context) error {
+			ran = true
+			return nil
+		})
+		sd.Trigger()
+		err := sd.Wait(t.Context())
+		var pe *PanicError
+		if !errors.As(err, &pe) || pe.Value != "boom" {
+			t.Fatalf("expected *PanicError, got %v", err)
+		}
+		if !ran {
+			t.Fatal("expected later phase to run")
+		}
+	})
+	t.Run("context is cancelled on trigger", func(t *testing.T) {
+		var sd Shutdown
+		ctx := sd.Context()
+		if ctx.Err() != nil {
+			t.Fatal("expected context not to be cancelled before shutdown")
+		}
+		sd.Trigger()
+		sd.Trigger()
+		<-ctx.Done()
+		<-sd.Done()
+	})
+	t.Run("register after trigger", func(t *testing.T) {
+		var sd Shutdown
+		sd.Trigger()
+		if err := sd.Register("late", 0, 0, func(context.Context) error { return nil }); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+	})
+	t.Run("wait gives up when ctx is done", func(t *testing.T) {
+		var sd Shutdown
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		if err := sd.Wait(ctx); err != context.Canceled {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+	})
+}
+
+func TestShutdown_Notify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("can not send os.Interrupt on windows")
+	}
+	var sd Shutdown
+	stop := sd.Notify(os.Interrupt)
+	defer stop()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sd.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected signal to trigger shutdown")
+	}
+}
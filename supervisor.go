@@ -0,0 +1,333 @@
+package syncx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+// Strategy decides which children a [Supervisor] restarts when one of them
+// exits.
+type Strategy int
+
+const (
+	// OneForOne restarts only the child that exited.
+	OneForOne Strategy = iota
+	// OneForAll stops and restarts every child when one exits.
+	OneForAll
+	// RestForOne stops and restarts the child that exited and every child
+	// added after it.
+	RestForOne
+)
+
+// Supervisor keeps long-running goroutines alive by restarting them when they
+// exit, whether they return an error, return nil or panic. Restarts are
+// delayed by an exponential backoff, and the supervisor gives up once children
+// restart too often.
+//
+// The zero value restarts each child on its own, is ready to use and never
+// gives up. Fields must be set before first use. A Supervisor must not be
+// copied after first use.
+//
+//	var s syncx.Supervisor
+//	s.Go("consumer", consume)
+//	s.Go("reporter", report)
+//	defer s.Stop(ctx)
+type Supervisor struct {
+	// Strategy decides which children are restarted when one exits.
+	Strategy Strategy
+	// Backoff is the delay before the first restart in a Period. It doubles
+	// with each further restart in the Period. Defaults to 100ms.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between restarts. Defaults to 10s.
+	MaxBackoff time.Duration
+	// MaxRestarts is the number of restarts allowed within Period before the
+	// supervisor gives up and stops every child. Zero means no limit.
+	MaxRestarts int
+	// Period is the window restarts are counted in. Defaults to 5s.
+	Period time.Duration
+	// OnStart, if set, is called before each run of a child.
+	OnStart func(name string)
+	// OnStop, if set, is called when a child exits after the supervisor
+	// stopped it.
+	OnStop func(name string, err error)
+	// OnCrash, if set, is called when a child exits on its own. err is nil if
+	// the child returned nil, and a [*PanicError] if it panicked.
+	OnCrash func(name string, err error)
+	// Clock optionally overrides the system clock. Must be set before first
+	// use.
+	Clock Clock
+
+	mu       Mutex
+	wg       WaitGroup
+	children []*child
+	// restarts holds the times of recent restarts, oldest first.
+	restarts []time.Time
+	// restartID identifies the most recently scheduled restart.
+	restartID uint64
+	halted    bool
+	// halt is closed once the supervisor stops or gives up. done is closed
+	// once every child has exited after that.
+	halt chan struct{}
+	done chan struct{}
+	err  error
+}
+
+// child is a goroutine managed by a [Supervisor].
+type child struct {
+	name string
+	f    func(ctx context.Context) error
+	// cancel and exited belong to the current run. exited is closed once the
+	// run returns.
+	cancel  context.CancelFunc
+	exited  chan struct{}
+	running bool
+	// stopping is set when the supervisor cancelled the current run.
+	stopping bool
+	// restart identifies the pending restart that will start the child
+	// again. It is zero if none is pending.
+	restart uint64
+}
+
+// Go adds a child named name that runs f until the supervisor is stopped,
+// restarting it whenever it exits. f must return once its context is
+// cancelled. Returns [ErrClosed] if the supervisor has been stopped or has
+// given up.
+func (s *Supervisor) Go(name string, f func(ctx context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.halted {
+		return ErrClosed
+	}
+	c := &child{name: name, f: f}
+	s.children = append(s.children, c)
+	s.start(c)
+	return nil
+}
+
+// Stop cancels every child and waits for them to exit, or for ctx to be done.
+// Children are not restarted after Stop is called. Returns the context's error
+// if ctx is done first.
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stop()
+	s.mu.Unlock()
+	return s.wg.WaitContext(ctx)
+}
+
+// Done returns a channel that is closed once the supervisor has been stopped
+// or has given up, and every child has exited.
+func (s *Supervisor) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	return s.done
+}
+
+// Err returns an error wrapping [ErrTooManyRestarts] and the last exit if the
+// supervisor gave up, or nil otherwise.
+func (s *Supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// start runs c in a new goroutine. Must hold mu.
+func (s *Supervisor) start(c *child) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	err := s.wg.TryGo(func() {
+		if s.OnStart != nil {
+			s.OnStart(c.name)
+		}
+		err := s.run(ctx, c.f)
+		cancel()
+		s.exit(c, exited, err)
+	})
+	if err != nil {
+		cancel()
+		return
+	}
+	c.cancel = cancel
+	c.exited = exited
+	c.running = true
+	c.stopping = false
+}
+
+// run calls f, turning a panic into a [*PanicError].
+func (s *Supervisor) run(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f(ctx)
+}
+
+// exit handles the end of a run of c, reports it and schedules a restart if
+// the child crashed.
+func (s *Supervisor) exit(c *child, exited chan struct{}, err error) {
+	s.mu.Lock()
+	c.running = false
+	close(exited)
+	if c.stopping || s.halted {
+		s.mu.Unlock()
+		if s.OnStop != nil {
+			s.OnStop(c.name, err)
+		}
+		return
+	}
+	id, delay, waits, ok := s.schedule(c, err)
+	s.mu.Unlock()
+	if s.OnCrash != nil {
+		s.OnCrash(c.name, err)
+	}
+	if !ok {
+		return
+	}
+	// the run is still counted, so the supervisor can't have finished
+	// waiting, but it may have been stopped since.
+	s.wg.TryGo(func() {
+		s.restartAfter(id, delay, waits)
+	})
+}
+
+// schedule plans a restart after c crashed with err, stopping the siblings
+// the strategy restarts with it. Reports false if the supervisor gave up
+// instead. Must hold mu.
+func (s *Supervisor) schedule(c *child, err error) (id uint64, delay time.Duration, waits []chan struct{}, ok bool) {
+	now := s.clock().Now()
+	period := s.Period
+	if period <= 0 {
+		period = 5 * time.Second
+	}
+	recent := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < period {
+			recent = append(recent, t)
+		}
+	}
+	s.restarts = append(recent, now)
+	if s.MaxRestarts > 0 && len(s.restarts) > s.MaxRestarts {
+		if err == nil {
+			s.err = fmt.Errorf("%w: %s exited", ErrTooManyRestarts, c.name)
+		} else {
+			s.err = fmt.Errorf("%w: %s: %w", ErrTooManyRestarts, c.name, err)
+		}
+		s.stop()
+		return 0, 0, nil, false
+	}
+
+	s.restartID++
+	id = s.restartID
+	crashed := slices.Index(s.children, c)
+	for i, sibling := range s.children {
+		switch {
+		case sibling == c:
+		case s.Strategy == OneForAll:
+		case s.Strategy == RestForOne && i > crashed:
+		default:
+			continue
+		}
+		sibling.restart = id
+		if sibling.running {
+			sibling.stopping = true
+			sibling.cancel()
+			waits = append(waits, sibling.exited)
+		}
+	}
+	return id, s.backoff(len(s.restarts)), waits, true
+}
+
+// backoff returns the delay before the nth restart in a period.
+func (s *Supervisor) backoff(n int) time.Duration {
+	d := s.Backoff
+	if d <= 0 {
+		d = 100 * time.Millisecond
+	}
+	limit := s.MaxBackoff
+	if limit <= 0 {
+		limit = 10 * time.Second
+	}
+	for range n - 1 {
+		if d >= limit/2 {
+			return limit
+		}
+		d *= 2
+	}
+	return min(d, limit)
+}
+
+// restartAfter starts the children of restart id once delay has elapsed and the
+// stopped siblings have exited, unless another restart took them over.
+func (s *Supervisor) restartAfter(id uint64, delay time.Duration, waits []chan struct{}) {
+	s.mu.Lock()
+	halt := s.haltCh()
+	s.mu.Unlock()
+	select {
+	case <-s.clock().After(delay):
+	case <-halt:
+		return
+	}
+	for _, exited := range waits {
+		select {
+		case <-exited:
+		case <-halt:
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.halted {
+		return
+	}
+	for _, c := range s.children {
+		if c.restart == id {
+			c.restart = 0
+			s.start(c)
+		}
+	}
+}
+
+// stop cancels every child and stops restarting them. Must hold mu.
+func (s *Supervisor) stop() {
+	if s.halted {
+		return
+	}
+	s.halted = true
+	close(s.haltCh())
+	for _, c := range s.children {
+		c.restart = 0
+		if c.running {
+			c.stopping = true
+			c.cancel()
+		}
+	}
+	s.wg.Close()
+	if s.done == nil {
+		s.done = make(chan struct{})
+	}
+	go func(done chan struct{}) {
+		s.wg.Wait()
+		close(done)
+	}(s.done)
+}
+
+// haltCh returns the channel closed on stop. Must hold mu.
+func (s *Supervisor) haltCh() chan struct{} {
+	if s.halt == nil {
+		s.halt = make(chan struct{})
+	}
+	return s.halt
+}
+
+func (s *Supervisor) clock() Clock {
+	if s.Clock == nil {
+		return systemClock{}
+	}
+	return s.Clock
+}
@@ -0,0 +1,240 @@
+package syncx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// superviseChildren adds children that report each start on the returned
+// channel and exit with an error when sent to on their crash channel.
+func superviseChildren(t *testing.T, s *Supervisor, names ...string) (<-chan string, map[string]chan struct{}) {
+	t.Helper()
+	started := make(chan string, 100)
+	crash := make(map[string]chan struct{})
+	for _, name := range names {
+		ch := make(chan struct{})
+		crash[name] = ch
+		err := s.Go(name, func(ctx context.Context) error {
+			started <- name
+			select {
+			case <-ch:
+				return errors.New("crashed")
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		s.Stop(context.Background())
+	})
+	for range names {
+		<-started
+	}
+	return started, crash
+}
+
+// receiveStarts returns the names of the next n children to start, sorted.
+func receiveStarts(t *testing.T, started <-chan string, n int) []string {
+	t.Helper()
+	var got []string
+	for range n {
+		select {
+		case name := <-started:
+			got = append(got, name)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d restarts, got %v", n, got)
+		}
+	}
+	slices.Sort(got)
+	select {
+	case name := <-started:
+		t.Fatalf("expected only %v to restart, %s restarted too", got, name)
+	case <-time.After(10 * time.Millisecond):
+	}
+	return got
+}
+
+func TestSupervisor_strategies(t *testing.T) {
+	tests := []struct {
+		strategy Strategy
+		crash    string
+		want     []string
+	}{
+		{OneForOne, "b", []string{"b"}},
+		{OneForAll, "b", []string{"a", "b", "c"}},
+		{RestForOne, "b", []string{"b", "c"}},
+		{RestForOne, "c", []string{"c"}},
+	}
+	for _, tt := range tests {
+		s := &Supervisor{Strategy: tt.strategy, Backoff: time.Millisecond}
+		started, crash := superviseChildren(t, s, "a", "b", "c")
+		crash[tt.crash] <- struct{}{}
+		if got := receiveStarts(t, started, len(tt.want)); !slices.Equal(got, tt.want) {
+			t.Fatalf("strategy %d: expected %v to restart, got %v", tt.strategy, tt.want, got)
+		}
+	}
+}
+
+func TestSupervisor_events(t *testing.T) {
+	events := make(chan string, 100)
+	s := &Supervisor{
+		Strategy: OneForAll,
+		Backoff:  time.Millisecond,
+		OnStart: func(name string) {
+			events <- "start " + name
+		},
+		OnStop: func(name string, err error) {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected %s to stop with context canceled, got %v", name, err)
+			}
+			events <- "stop " + name
+		},
+		OnCrash: func(name string, err error) {
+			if err == nil {
+				t.Errorf("expected %s to crash with an error", name)
+			}
+			events <- "crash " + name
+		},
+	}
+	started, crash := superviseChildren(t, s, "a", "b")
+	crash["a"] <- struct{}{}
+	receiveStarts(t, started, 2)
+	s.Stop(t.Context())
+	close(events)
+	var got []string
+	for e := range events {
+		got = append(got, e)
+	}
+	slices.Sort(got)
+	want := []string{
+		"crash a",
+		"start a", "start a",
+		"start b", "start b",
+		"stop a",
+		"stop b", "stop b",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+}
+
+func TestSupervisor_panic(t *testing.T) {
+	crashed := make(chan error, 1)
+	s := &Supervisor{
+		Backoff: time.Hour,
+		OnCrash: func(_ string, err error) {
+			crashed <- err
+		},
+	}
+	defer s.Stop(t.Context())
+	s.Go("panics", func(context.Context) error {
+		panic("boom")
+	})
+	var pe *PanicError
+	if err := <-crashed; !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Fatalf("expected crash with *PanicError, got %v", err)
+	}
+}
+
+func TestSupervisor_maxRestarts(t *testing.T) {
+	oops := errors.New("oops")
+	var starts int
+	s := &Supervisor{Backoff: time.Nanosecond, MaxRestarts: 3}
+	s.Go("fails", func(context.Context) error {
+		starts++
+		return oops
+	})
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected supervisor to give up")
+	}
+	if starts != 4 {
+		t.Fatalf("expected 1 start and 3 restarts, got %d starts", starts)
+	}
+	if err := s.Err(); !errors.Is(err, ErrTooManyRestarts) || !errors.Is(err, oops) {
+		t.Fatalf("expected too many restarts wrapping %v, got %v", oops, err)
+	}
+	if err := s.Go("late", func(context.Context) error { return nil }); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestSupervisor_backoff(t *testing.T) {
+	clock := newFakeClock()
+	started := make(chan struct{}, 10)
+	s := &Supervisor{Clock: clock, Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	defer s.Stop(t.Context())
+	s.Go("fails", func(context.Context) error {
+		started <- struct{}{}
+		return errors.New("oops")
+	})
+	<-started
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		clock.BlockUntil(1)
+		clock.Advance(delay - time.Nanosecond)
+		select {
+		case <-started:
+			t.Fatalf("expected restart to wait %v", delay)
+		case <-time.After(5 * time.Millisecond):
+		}
+		clock.Advance(time.Nanosecond)
+		<-started
+	}
+}
+
+func TestSupervisor_Stop(t *testing.T) {
+	t.Run("waits for children", func(t *testing.T) {
+		var s Supervisor
+		exited := make(chan struct{})
+		s.Go("waits", func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Millisecond)
+			close(exited)
+			return nil
+		})
+		if err := s.Stop(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-exited:
+		default:
+			t.Fatal("expected Stop to wait for the child to exit")
+		}
+		<-s.Done()
+		if err := s.Err(); err != nil {
+			t.Fatalf("expected nil error after Stop, got %v", err)
+		}
+	})
+	t.Run("gives up waiting when ctx is done", func(t *testing.T) {
+		var s Supervisor
+		release := make(chan struct{})
+		defer close(release)
+		s.Go("stuck", func(context.Context) error {
+			<-release
+			return nil
+		})
+		ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond)
+		defer cancel()
+		if err := s.Stop(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	})
+	t.Run("cancels pending restarts", func(t *testing.T) {
+		s := Supervisor{Backoff: time.Hour}
+		s.Go("fails", func(context.Context) error {
+			return errors.New("oops")
+		})
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		if err := s.Stop(ctx); err != nil {
+			t.Fatalf("expected Stop not to wait for the backoff, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package syncx
+
+import (
+	"sync"
+	"time"
+)
+
+// timers pools stopped timers so that timed operations don't allocate a new
+// timer on every call.
+var timers = sync.Pool{
+	New: func() any {
+		t := time.NewTimer(time.Hour)
+		t.Stop()
+		return t
+	},
+}
+
+// getTimer gets a pooled timer that fires after d. Return it with [putTimer].
+func getTimer(d time.Duration) *time.Timer {
+	t := timers.Get().(*time.Timer)
+	t.Reset(d)
+	return t
+}
+
+// putTimer stops t and returns it to the pool. Since Go 1.23 a stopped timer
+// never delivers a stale value, so t is safe to reuse.
+func putTimer(t *time.Timer) {
+	t.Stop()
+	timers.Put(t)
+}
@@ -2,6 +2,11 @@ package syncx
 
 import (
 	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jakobii/syncx/gatomic"
 )
@@ -28,23 +33,74 @@ type WaitGroup struct {
 	mu Mutex
 	ch gatomic.Value[chan struct{}]
 	n  int
+	// closed rejects positive deltas once set.
+	closed bool
+	// closeAll is set by CloseAll to reject positive deltas in descendants.
+	closeAll bool
+	// parent is counting this group as one task while its counter is
+	// positive.
+	parent *WaitGroup
+	// changed is closed and replaced whenever n changes. It is nil when nobody
+	// is waiting.
+	changed chan struct{}
+	// below holds channels to close once n drops below their threshold.
+	below []thresholdWaiter
+	// tasks holds the outstanding tasks started with GoNamed.
+	tasks map[*task]struct{}
+	// drained is closed once the group is sealed and n is zero. It is nil
+	// when nobody is waiting. isDrained is set once that has happened.
+	drained   chan struct{}
+	isDrained bool
+	// watchers holds descendants waiting on AwaitClosed, which learn of
+	// CloseAll through this group.
+	watchers map[*WaitGroup]struct{}
 }
 
-// Add adds delta to the WaitGroup counter.
+// thresholdWaiter is a channel to close once a counter crosses n.
+type thresholdWaiter struct {
+	n  int
+	ch chan struct{}
+}
+
+// Add adds delta to the WaitGroup counter. Panics if delta is positive and the
+// WaitGroup has been closed.
 func (wg *WaitGroup) Add(delta int) {
+	if err := wg.TryAdd(delta); err != nil {
+		panic("add to closed WaitGroup")
+	}
+}
+
+// TryAdd adds delta to the WaitGroup counter. Returns [ErrClosed] if delta is
+// positive and the WaitGroup has been closed.
+func (wg *WaitGroup) TryAdd(delta int) error {
 	wg.mu.Lock()
 	defer wg.mu.Unlock()
+	return wg.add(delta)
+}
+
+// add adds delta to the counter. Must hold mu.
+func (wg *WaitGroup) add(delta int) error {
 	currentCount := wg.n
 	// no-op.
 	if delta == 0 {
-		return
+		return nil
 	}
 	// detect negative counter.
 	if currentCount+delta < 0 {
 		panic("negative WaitGroup counter")
 	}
+	// reject new tasks once closed.
+	if delta > 0 && wg.sealed() {
+		return ErrClosed
+	}
 	// init wait ch on first addition to the group.
 	if currentCount == 0 {
+		// the parent may be closed too.
+		if wg.parent != nil {
+			if err := wg.parent.TryAdd(1); err != nil {
+				return err
+			}
+		}
 		wg.ch.Store(make(chan struct{}))
 	}
 	// mutate count.
@@ -53,7 +109,127 @@ func (wg *WaitGroup) Add(delta int) {
 	if wg.n == 0 {
 		ch := wg.ch.Load()
 		close(ch)
+		if wg.parent != nil {
+			wg.parent.Done()
+		}
+	}
+	// signal subscribers.
+	if wg.changed != nil {
+		close(wg.changed)
+		wg.changed = nil
+	}
+	if delta < 0 {
+		wg.releaseBelow()
+		wg.releaseDrained()
+	}
+	return nil
+}
+
+// sealed reports whether wg or an ancestor closed with CloseAll rejects new
+// tasks. Must hold mu. Locks ancestors, which never lock their descendants.
+func (wg *WaitGroup) sealed() bool {
+	if wg.closed {
+		return true
+	}
+	for p := wg.parent; p != nil; p = p.parent {
+		p.mu.Lock()
+		closeAll := p.closeAll
+		p.mu.Unlock()
+		if closeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseDrained closes the channel returned by AwaitClosed once the group is
+// sealed and n is zero. Must hold mu. Locks ancestors.
+func (wg *WaitGroup) releaseDrained() {
+	if wg.isDrained || wg.n != 0 || !wg.sealed() {
+		return
+	}
+	wg.isDrained = true
+	if wg.drained != nil {
+		close(wg.drained)
+	}
+	for p := wg.parent; p != nil; p = p.parent {
+		p.mu.Lock()
+		delete(p.watchers, wg)
+		p.mu.Unlock()
+	}
+}
+
+// releaseBelow closes the channels of AwaitBelow callers whose threshold the
+// counter has dropped below. Must hold mu.
+func (wg *WaitGroup) releaseBelow() {
+	waiting := wg.below[:0]
+	for _, w := range wg.below {
+		if wg.n < w.n {
+			close(w.ch)
+			continue
+		}
+		waiting = append(waiting, w)
+	}
+	clear(wg.below[len(waiting):])
+	wg.below = waiting
+}
+
+// Count returns the current value of the WaitGroup counter, which is the
+// number of outstanding tasks.
+func (wg *WaitGroup) Count() int {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	return wg.n
+}
+
+// Changes returns a channel that will be closed the next time the WaitGroup
+// counter changes. Get the channel before reading [WaitGroup.Count] so that no
+// change is missed:
+//
+//	for {
+//	    changed := wg.Changes()
+//	    report(wg.Count())
+//	    select {
+//	    case <-changed:
+//	    case <-ctx.Done():
+//	        return
+//	    }
+//	}
+func (wg *WaitGroup) Changes() <-chan struct{} {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if wg.changed == nil {
+		wg.changed = make(chan struct{})
 	}
+	return wg.changed
+}
+
+// AwaitBelow returns a channel that will be closed when the WaitGroup counter
+// is below n. If it already is, the channel is closed. This is useful for
+// limiting how many tasks are in flight:
+//
+//	select {
+//	case <-wg.AwaitBelow(limit):
+//	    wg.Go(task)
+//	case <-ctx.Done():
+//	}
+//
+// The counter never drops below zero, so for n <= 0 the channel never closes.
+// A channel that is abandoned before it closes stays registered until the
+// counter drops below n.
+func (wg *WaitGroup) AwaitBelow(n int) <-chan struct{} {
+	ch := make(chan struct{})
+	if n <= 0 {
+		return ch
+	}
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if wg.n < n {
+		close(ch)
+		return ch
+	}
+	wg.below = append(wg.below, thresholdWaiter{n: n, ch: ch})
+	return ch
 }
 
 // Done decrements the WaitGroup counter by one. Short for calling
@@ -62,14 +238,157 @@ func (wg *WaitGroup) Done() {
 	wg.Add(-1)
 }
 
-// Go runs f in a new goroutine and adds it to the WaitGroup. Short for calling
-// [WaitGroup.Add] and [WaitGroup.Done].
+// Go runs f in a new goroutine and adds it to the WaitGroup. Panics if the
+// WaitGroup has been closed. Short for calling [WaitGroup.Add] and
+// [WaitGroup.Done].
 func (wg *WaitGroup) Go(f func()) {
-	wg.Add(1)
+	if err := wg.TryGo(f); err != nil {
+		panic("go on closed WaitGroup")
+	}
+}
+
+// TryGo runs f in a new goroutine and adds it to the WaitGroup. Returns
+// [ErrClosed] without running f if the WaitGroup has been closed.
+func (wg *WaitGroup) TryGo(f func()) error {
+	if err := wg.TryAdd(1); err != nil {
+		return err
+	}
 	go func() {
 		defer wg.Done()
 		f()
 	}()
+	return nil
+}
+
+// Close stops the WaitGroup from accepting new tasks. Adding a positive delta
+// afterwards panics, or fails with [ErrClosed] via [WaitGroup.TryAdd] and
+// [WaitGroup.TryGo]. Tasks already in flight can still call Done, so the
+// counter can only drain, and a subsequent [WaitGroup.Await] is a race-free
+// shutdown fence. [WaitGroup.AwaitClosed] is the same fence for waiters that
+// start before Close:
+//
+//	// shutdown
+//	wg.Close()
+//	err := wg.WaitContext(ctx)
+//
+//	// request handler
+//	if err := wg.TryGo(handle); err != nil {
+//	    return errShuttingDown
+//	}
+//
+// Calling Close more than once is a no-op.
+func (wg *WaitGroup) Close() {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	wg.closed = true
+	wg.releaseDrained()
+}
+
+// AwaitClosed returns a channel that will be closed once the WaitGroup, or an
+// ancestor with [WaitGroup.CloseAll], has been closed and its counter has
+// reached zero. Unlike [WaitGroup.Await], it stays
+// open while the counter is briefly zero before [WaitGroup.Close] is called,
+// so it can be obtained before shutdown begins:
+//
+//	stopped := wg.AwaitClosed()
+//	go serve(&wg)
+//	<-shutdown
+//	wg.Close()
+//	<-stopped
+func (wg *WaitGroup) AwaitClosed() <-chan struct{} {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	if wg.drained != nil {
+		return wg.drained
+	}
+	wg.drained = make(chan struct{})
+	if wg.isDrained {
+		close(wg.drained)
+		return wg.drained
+	}
+	// an ancestor's CloseAll can seal wg without touching it.
+	if !wg.closed {
+		for p := wg.parent; p != nil; p = p.parent {
+			p.mu.Lock()
+			if p.watchers == nil {
+				p.watchers = make(map[*WaitGroup]struct{})
+			}
+			p.watchers[wg] = struct{}{}
+			p.mu.Unlock()
+		}
+	}
+	wg.releaseDrained()
+	return wg.drained
+}
+
+// Closed reports whether [WaitGroup.Close] has been called.
+func (wg *WaitGroup) Closed() bool {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	return wg.closed
+}
+
+// CloseAll closes the WaitGroup and all of its descendants created with
+// [WaitGroup.Child], including ones that still have tasks in flight. Calling
+// CloseAll more than once is a no-op.
+func (wg *WaitGroup) CloseAll() {
+	wg.mu.Lock()
+	wg.closed = true
+	wg.closeAll = true
+	wg.releaseDrained()
+	watchers := wg.watchers
+	wg.watchers = nil
+	wg.mu.Unlock()
+	// descendants lock their ancestors, so they must be locked without
+	// holding mu.
+	for d := range watchers {
+		d.mu.Lock()
+		d.releaseDrained()
+		d.mu.Unlock()
+	}
+}
+
+// Child returns a new WaitGroup whose tasks also count towards wg. While the
+// child's counter is positive it counts as one task of wg, so wg's
+// [WaitGroup.Await] closes only once every child is done.
+//
+// Closing wg stops idle children from starting new tasks, since they can no
+// longer add themselves to wg. Busy children keep accepting tasks until they
+// are closed themselves or wg is closed with [WaitGroup.CloseAll].
+//
+//	var service syncx.WaitGroup
+//	db := service.Child()
+//	http := service.Child()
+//	db.Go(flush)
+//	http.Go(serve)
+//	<-service.Await() // waits for both.
+func (wg *WaitGroup) Child() *WaitGroup {
+	return &WaitGroup{parent: wg}
+}
+
+// AwaitAll returns a channel that will be closed once the counter of each of
+// wgs has reached zero. Like [WaitGroup.Await], call [WaitGroup.Add] before
+// calling AwaitAll. A goroutine waits for the groups one after another and
+// exits once the channel is closed, or once ctx is done, in which case the
+// channel is never closed:
+//
+//	select {
+//	case <-syncx.AwaitAll(ctx, &db, &http):
+//	case <-ctx.Done():
+//	}
+func AwaitAll(ctx context.Context, wgs ...*WaitGroup) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		for _, wg := range wgs {
+			select {
+			case <-wg.Await():
+			case <-ctx.Done():
+				return
+			}
+		}
+		close(ch)
+	}()
+	return ch
 }
 
 // Wait blocks until the WaitGroup counter reaches zero. Short for calling
@@ -97,12 +416,20 @@ func (wg *WaitGroup) Wait() {
 //	}()
 //	<-wg.Await()
 func (wg *WaitGroup) Await() <-chan struct{} {
+	ch := wg.await()
+	if ch == nil {
+		ch = make(chan struct{})
+		close(ch)
+	}
+	return ch
+}
+
+// await returns the current wait channel, or nil if the counter is zero.
+func (wg *WaitGroup) await() chan struct{} {
 	wg.mu.Lock()
 	defer wg.mu.Unlock()
 	if wg.n == 0 {
-		ch := make(chan struct{})
-		close(ch)
-		return ch
+		return nil
 	}
 	return wg.ch.Load()
 }
@@ -118,3 +445,175 @@ func (wg *WaitGroup) WaitContext(ctx context.Context) error {
 		return nil
 	}
 }
+
+// WaitTimeout waits for the WaitGroup counter to reach zero and reports whether
+// it did so before d elapsed. It does not allocate when the counter is already
+// zero.
+func (wg *WaitGroup) WaitTimeout(d time.Duration) bool {
+	ch := wg.await()
+	if ch == nil {
+		return true
+	}
+	if d <= 0 {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+	t := getTimer(d)
+	defer putTimer(t)
+	select {
+	case <-ch:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// Task describes an outstanding task started with [WaitGroup.GoNamed].
+type Task struct {
+	// Name is the name the task was started with.
+	Name string
+	// Start is when the task was started.
+	Start time.Time
+	// Stack is the call stack that started the task.
+	Stack string
+}
+
+// task is an outstanding task started with GoNamed.
+type task struct {
+	name  string
+	start time.Time
+	pcs   []uintptr
+}
+
+// GoNamed is like [WaitGroup.Go] but records name, the start time and the call
+// stack of the task until it finishes, so that [WaitGroup.Tasks] can tell what
+// is still running. Panics if the WaitGroup has been closed.
+func (wg *WaitGroup) GoNamed(name string, f func()) {
+	if err := wg.goNamed(name, f); err != nil {
+		panic("go on closed WaitGroup")
+	}
+}
+
+// TryGoNamed is like [WaitGroup.GoNamed] but returns [ErrClosed] without
+// running f if the WaitGroup has been closed.
+func (wg *WaitGroup) TryGoNamed(name string, f func()) error {
+	return wg.goNamed(name, f)
+}
+
+// goNamed runs f as a named task. It must be called directly by an exported
+// method so that the recorded stack starts at their caller.
+func (wg *WaitGroup) goNamed(name string, f func()) error {
+	pcs := make([]uintptr, 32)
+	t := &task{
+		name:  name,
+		start: time.Now(),
+		pcs:   pcs[:runtime.Callers(3, pcs)],
+	}
+	wg.mu.Lock()
+	if err := wg.add(1); err != nil {
+		wg.mu.Unlock()
+		return err
+	}
+	if wg.tasks == nil {
+		wg.tasks = make(map[*task]struct{})
+	}
+	wg.tasks[t] = struct{}{}
+	wg.mu.Unlock()
+	go func() {
+		defer func() {
+			wg.mu.Lock()
+			defer wg.mu.Unlock()
+			delete(wg.tasks, t)
+			wg.add(-1)
+		}()
+		f()
+	}()
+	return nil
+}
+
+// Tasks returns the outstanding tasks started with [WaitGroup.GoNamed], oldest
+// first. Tasks started with [WaitGroup.Go] or tracked with [WaitGroup.Add] are
+// not included.
+func (wg *WaitGroup) Tasks() []Task {
+	wg.mu.Lock()
+	tasks := make([]*task, 0, len(wg.tasks))
+	for t := range wg.tasks {
+		tasks = append(tasks, t)
+	}
+	wg.mu.Unlock()
+	slices.SortFunc(tasks, func(a, b *task) int {
+		return a.start.Compare(b.start)
+	})
+	out := make([]Task, len(tasks))
+	for i, t := range tasks {
+		out[i] = Task{Name: t.name, Start: t.start, Stack: formatStack(t.pcs)}
+	}
+	return out
+}
+
+// WaitReport is like [WaitGroup.WaitContext] but when ctx is done first it
+// returns an [*OutstandingError] describing what was still running.
+//
+//	if err := wg.WaitReport(ctx); err != nil {
+//	    log.Printf("shutdown: %v", err)
+//	}
+func (wg *WaitGroup) WaitReport(ctx context.Context) error {
+	err := wg.WaitContext(ctx)
+	if err == nil {
+		return nil
+	}
+	return &OutstandingError{
+		Err:   err,
+		Count: wg.Count(),
+		Tasks: wg.Tasks(),
+	}
+}
+
+// OutstandingError is returned by [WaitGroup.WaitReport] when it gives up
+// waiting.
+type OutstandingError struct {
+	// Err is the context error.
+	Err error
+	// Count is the WaitGroup counter when waiting gave up.
+	Count int
+	// Tasks are the outstanding named tasks, oldest first.
+	Tasks []Task
+}
+
+func (e *OutstandingError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v: %d outstanding", e.Err, e.Count)
+	now := time.Now()
+	for i, t := range e.Tasks {
+		sep := ", "
+		if i == 0 {
+			sep = ": "
+		}
+		fmt.Fprintf(&b, "%s%s (running %v)", sep, t.Name, now.Sub(t.Start).Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+func (e *OutstandingError) Unwrap() error {
+	return e.Err
+}
+
+// formatStack formats program counters like a goroutine trace.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
@@ -3,8 +3,11 @@ package syncx
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestWaitGroupAdd(t *testing.T) {
@@ -361,3 +364,551 @@ func TestWaitGroupGo(t *testing.T) {
 		}
 	})
 }
+
+func TestWaitGroupWaitTimeout(t *testing.T) {
+	t.Run("returns true when count is zero", func(t *testing.T) {
+		var wg WaitGroup
+		if !wg.WaitTimeout(0) {
+			t.Fatal("expected true when count is already zero")
+		}
+	})
+	t.Run("returns false on timeout", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(1)
+		if wg.WaitTimeout(time.Millisecond) {
+			t.Fatal("expected false when count never reaches zero")
+		}
+		if wg.WaitTimeout(0) {
+			t.Fatal("expected false for non positive duration")
+		}
+	})
+	t.Run("returns true when count reaches zero", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Go(func() {
+			time.Sleep(time.Millisecond)
+		})
+		if !wg.WaitTimeout(time.Minute) {
+			t.Fatal("expected true when count reaches zero")
+		}
+	})
+}
+
+func BenchmarkWaitGroupWaitTimeout(b *testing.B) {
+	var wg WaitGroup
+	b.ReportAllocs()
+	for b.Loop() {
+		wg.WaitTimeout(time.Second)
+	}
+}
+
+func BenchmarkWaitGroupWaitContext(b *testing.B) {
+	var wg WaitGroup
+	b.ReportAllocs()
+	for b.Loop() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		wg.WaitContext(ctx)
+		cancel()
+	}
+}
+
+func TestWaitGroupCount(t *testing.T) {
+	var wg WaitGroup
+	if n := wg.Count(); n != 0 {
+		t.Fatalf("expected count to be 0, got %d", n)
+	}
+	wg.Add(3)
+	wg.Done()
+	if n := wg.Count(); n != 2 {
+		t.Fatalf("expected count to be 2, got %d", n)
+	}
+}
+
+func TestWaitGroupChanges(t *testing.T) {
+	t.Run("closes on add", func(t *testing.T) {
+		var wg WaitGroup
+		ch := wg.Changes()
+		select {
+		case <-ch:
+			t.Fatal("expected open channel before any change")
+		default:
+		}
+		wg.Add(1)
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected channel to be closed after Add(1)")
+		}
+	})
+	t.Run("closes on done", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(1)
+		ch := wg.Changes()
+		wg.Done()
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected channel to be closed after Done()")
+		}
+	})
+	t.Run("does not close on add zero", func(t *testing.T) {
+		var wg WaitGroup
+		ch := wg.Changes()
+		wg.Add(0)
+		select {
+		case <-ch:
+			t.Fatal("expected open channel after Add(0)")
+		default:
+		}
+	})
+	t.Run("returns a new channel after a change", func(t *testing.T) {
+		var wg WaitGroup
+		ch1 := wg.Changes()
+		if ch2 := wg.Changes(); ch1 != ch2 {
+			t.Fatal("expected same channel until the counter changes")
+		}
+		wg.Add(1)
+		if ch2 := wg.Changes(); ch1 == ch2 {
+			t.Fatal("expected a new channel after the counter changes")
+		}
+	})
+}
+
+func TestWaitGroupAwaitBelow(t *testing.T) {
+	t.Run("closed when already below", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(1)
+		select {
+		case <-wg.AwaitBelow(2):
+		default:
+			t.Fatal("expected closed channel when count is below threshold")
+		}
+	})
+	t.Run("closes once count drops below", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(3)
+		ch := wg.AwaitBelow(2)
+		wg.Done()
+		select {
+		case <-ch:
+			t.Fatal("expected open channel when count equals threshold")
+		default:
+		}
+		wg.Add(1)
+		wg.Add(-2)
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected channel to be closed when count drops below threshold")
+		}
+		if len(wg.below) != 0 {
+			t.Fatalf("expected released waiters to be dropped, got %d", len(wg.below))
+		}
+	})
+	t.Run("never closes for a threshold of zero", func(t *testing.T) {
+		var wg WaitGroup
+		ch := wg.AwaitBelow(0)
+		select {
+		case <-ch:
+			t.Fatal("expected channel to stay open")
+		default:
+		}
+		if len(wg.below) != 0 {
+			t.Fatalf("expected no waiter to be registered, got %d", len(wg.below))
+		}
+	})
+	t.Run("limits tasks in flight", func(t *testing.T) {
+		var wg WaitGroup
+		limit := 3
+		var mu sync.Mutex
+		inFlight, peak := 0, 0
+		for range 20 {
+			<-wg.AwaitBelow(limit)
+			// only this goroutine adds, so the count can't rise in between.
+			wg.Go(func() {
+				mu.Lock()
+				inFlight++
+				peak = max(peak, inFlight)
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			})
+		}
+		wg.Wait()
+		if peak > limit {
+			t.Fatalf("expected at most %d tasks in flight, got %d", limit, peak)
+		}
+	})
+}
+
+func TestWaitGroupClose(t *testing.T) {
+	t.Run("try add fails once closed", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Close()
+		wg.Close()
+		if !wg.Closed() {
+			t.Fatal("expected Closed to report true")
+		}
+		if err := wg.TryAdd(1); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		if wg.n != 0 {
+			t.Fatalf("expected count to remain 0, got %d", wg.n)
+		}
+	})
+	t.Run("add panics once closed", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Close()
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic when adding to a closed WaitGroup")
+			}
+		}()
+		wg.Add(1)
+	})
+	t.Run("in flight tasks drain", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(2)
+		wg.Close()
+		if err := wg.TryAdd(-1); err != nil {
+			t.Fatalf("expected nil error for negative delta, got %v", err)
+		}
+		wg.Done()
+		select {
+		case <-wg.Await():
+		default:
+			t.Fatal("expected Await to be closed once drained")
+		}
+	})
+	t.Run("try go fails once closed", func(t *testing.T) {
+		var wg WaitGroup
+		if err := wg.TryGo(func() {}); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		wg.Close()
+		if err := wg.TryGo(func() { t.Error("f should not run") }); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		wg.Wait()
+	})
+	t.Run("go panics once closed", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Close()
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic when calling Go on a closed WaitGroup")
+			}
+		}()
+		wg.Go(func() {})
+	})
+}
+
+func TestWaitGroupAwaitClosed(t *testing.T) {
+	t.Run("stays open at zero until closed", func(t *testing.T) {
+		var wg WaitGroup
+		ch := wg.AwaitClosed()
+		wg.Add(1)
+		wg.Done()
+		select {
+		case <-ch:
+			t.Fatal("expected channel to stay open before Close")
+		default:
+		}
+		wg.Close()
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected channel to be closed once closed at zero")
+		}
+	})
+	t.Run("closes once drained after close", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Add(2)
+		ch := wg.AwaitClosed()
+		wg.Close()
+		wg.Done()
+		select {
+		case <-ch:
+			t.Fatal("expected channel to stay open while tasks are in flight")
+		default:
+		}
+		wg.Done()
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected channel to be closed once drained")
+		}
+	})
+	t.Run("closes after an ancestor's CloseAll", func(t *testing.T) {
+		var parent WaitGroup
+		idle := parent.Child().Child()
+		busy := parent.Child()
+		idleClosed := idle.AwaitClosed()
+		busy.Add(1)
+		busyClosed := busy.AwaitClosed()
+		parent.CloseAll()
+		select {
+		case <-idleClosed:
+		case <-time.After(time.Second):
+			t.Fatal("expected idle descendant to be released by CloseAll")
+		}
+		select {
+		case <-busyClosed:
+			t.Fatal("expected busy child to stay open while tasks are in flight")
+		default:
+		}
+		busy.Done()
+		<-busyClosed
+		if len(parent.watchers) != 0 {
+			t.Fatalf("expected watchers to be dropped, got %d", len(parent.watchers))
+		}
+		select {
+		case <-parent.Child().AwaitClosed():
+		default:
+			t.Fatal("expected a child of a closed group to be closed already")
+		}
+	})
+	t.Run("closed when called after draining", func(t *testing.T) {
+		var wg WaitGroup
+		wg.CloseAll()
+		wg.Close()
+		select {
+		case <-wg.AwaitClosed():
+		default:
+			t.Fatal("expected closed channel")
+		}
+	})
+}
+
+// Tasks started concurrently with Close must either be rejected or be waited
+// for. Must be tested with "-race".
+func TestWaitGroupClose_race(t *testing.T) {
+	var wg WaitGroup
+	var accepted, finished atomic.Int64
+	var callers sync.WaitGroup
+	for range 100 {
+		callers.Go(func() {
+			err := wg.TryGo(func() {
+				finished.Add(1)
+			})
+			if err == nil {
+				accepted.Add(1)
+			}
+		})
+	}
+	wg.Close()
+	wg.Wait()
+	finishedAtWait := finished.Load()
+	callers.Wait()
+	if n := accepted.Load(); n != finishedAtWait {
+		t.Fatalf("expected all %d accepted tasks to finish before Wait returns, got %d", n, finishedAtWait)
+	}
+}
+
+func TestWaitGroupGoNamed(t *testing.T) {
+	t.Run("tracks outstanding tasks", func(t *testing.T) {
+		var wg WaitGroup
+		release := make(chan struct{})
+		wg.GoNamed("first", func() { <-release })
+		wg.GoNamed("second", func() { <-release })
+		tasks := wg.Tasks()
+		if len(tasks) != 2 {
+			t.Fatalf("expected 2 tasks, got %d", len(tasks))
+		}
+		if tasks[0].Name != "first" || tasks[1].Name != "second" {
+			t.Fatalf("expected tasks oldest first, got %q and %q", tasks[0].Name, tasks[1].Name)
+		}
+		if tasks[0].Start.IsZero() {
+			t.Fatal("expected start time to be set")
+		}
+		if !strings.Contains(tasks[0].Stack, "TestWaitGroupGoNamed") {
+			t.Fatalf("expected stack to include the caller, got %s", tasks[0].Stack)
+		}
+		close(release)
+		wg.Wait()
+		if n := len(wg.Tasks()); n != 0 {
+			t.Fatalf("expected no tasks after Wait, got %d", n)
+		}
+	})
+	t.Run("panics once closed", func(t *testing.T) {
+		var wg WaitGroup
+		wg.Close()
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic when calling GoNamed on a closed WaitGroup")
+			}
+			if len(wg.tasks) != 0 {
+				t.Fatal("expected rejected task not to be tracked")
+			}
+		}()
+		wg.GoNamed("rejected", func() {})
+	})
+	t.Run("try fails once closed", func(t *testing.T) {
+		var wg WaitGroup
+		release := make(chan struct{})
+		if err := wg.TryGoNamed("accepted", func() { <-release }); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		tasks := wg.Tasks()
+		if len(tasks) != 1 || !strings.HasPrefix(tasks[0].Stack, "github.com/jakobii/syncx.TestWaitGroupGoNamed") {
+			t.Fatalf("expected stack to start at the caller, got %v", tasks)
+		}
+		wg.Close()
+		if err := wg.TryGoNamed("rejected", func() { t.Error("f should not run") }); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		close(release)
+		wg.Wait()
+	})
+}
+
+func TestWaitGroupWaitReport(t *testing.T) {
+	t.Run("returns nil when count reaches zero", func(t *testing.T) {
+		var wg WaitGroup
+		wg.GoNamed("quick", func() {})
+		if err := wg.WaitReport(t.Context()); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+	t.Run("reports outstanding tasks", func(t *testing.T) {
+		var wg WaitGroup
+		release := make(chan struct{})
+		defer close(release)
+		wg.GoNamed("stuck", func() { <-release })
+		wg.Add(1)
+		defer wg.Done()
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := wg.WaitReport(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled, got %v", err)
+		}
+		var oe *OutstandingError
+		if !errors.As(err, &oe) {
+			t.Fatalf("expected *OutstandingError, got %T", err)
+		}
+		if oe.Count != 2 || len(oe.Tasks) != 1 || oe.Tasks[0].Name != "stuck" {
+			t.Fatalf("unexpected report %+v", oe)
+		}
+		if msg := err.Error(); !strings.HasPrefix(msg, "context canceled: 2 outstanding: stuck (running ") {
+			t.Fatalf("unexpected error message %q", msg)
+		}
+	})
+}
+
+func TestWaitGroupChild(t *testing.T) {
+	t.Run("busy child counts as one parent task", func(t *testing.T) {
+		var parent WaitGroup
+		child := parent.Child()
+		child.Add(2)
+		if n := parent.Count(); n != 1 {
+			t.Fatalf("expected parent count to be 1, got %d", n)
+		}
+		child.Add(3)
+		if n := parent.Count(); n != 1 {
+			t.Fatalf("expected parent count to remain 1, got %d", n)
+		}
+		ch := parent.Await()
+		child.Add(-5)
+		select {
+		case <-ch:
+		default:
+			t.Fatal("expected parent Await to be closed once the child is done")
+		}
+	})
+	t.Run("parent waits for every child", func(t *testing.T) {
+		var parent WaitGroup
+		a, b := parent.Child(), parent.Child()
+		a.Add(1)
+		b.Add(1)
+		ch := parent.Await()
+		a.Done()
+		select {
+		case <-ch:
+			t.Fatal("expected parent Await to stay open while a child is busy")
+		default:
+		}
+		b.Done()
+		<-ch
+	})
+	t.Run("grandchildren propagate", func(t *testing.T) {
+		var root WaitGroup
+		leaf := root.Child().Child()
+		leaf.Go(func() {})
+		root.Wait()
+		if n := leaf.Count(); n != 0 {
+			t.Fatalf("expected leaf count to be 0, got %d", n)
+		}
+	})
+	t.Run("idle child rejects tasks once parent is closed", func(t *testing.T) {
+		var parent WaitGroup
+		child := parent.Child()
+		parent.Close()
+		if err := child.TryAdd(1); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		if n := child.Count(); n != 0 {
+			t.Fatalf("expected child count to remain 0, got %d", n)
+		}
+	})
+	t.Run("busy child accepts tasks once parent is closed", func(t *testing.T) {
+		var parent WaitGroup
+		child := parent.Child()
+		child.Add(1)
+		parent.Close()
+		if err := child.TryAdd(1); err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	})
+	t.Run("close all cascades to busy children", func(t *testing.T) {
+		var parent WaitGroup
+		child := parent.Child()
+		grandchild := child.Child()
+		grandchild.Add(1)
+		parent.CloseAll()
+		if err := grandchild.TryAdd(1); err != ErrClosed {
+			t.Fatalf("expected ErrClosed, got %v", err)
+		}
+		if child.Closed() {
+			t.Fatal("expected Closed to only report the child's own state")
+		}
+		grandchild.Done()
+		parent.Wait()
+	})
+}
+
+func TestAwaitAll(t *testing.T) {
+	t.Run("closes when all are done", func(t *testing.T) {
+		var a, b WaitGroup
+		a.Add(1)
+		b.Add(1)
+		ch := AwaitAll(t.Context(), &a, &b)
+		b.Done()
+		select {
+		case <-ch:
+			t.Fatal("expected open channel while a is busy")
+		case <-time.After(time.Millisecond):
+		}
+		a.Done()
+		<-ch
+	})
+	t.Run("closes with no groups", func(t *testing.T) {
+		<-AwaitAll(t.Context())
+	})
+	t.Run("goroutine exits when ctx is done", func(t *testing.T) {
+		var a WaitGroup
+		a.Add(1)
+		// a is still busy when the leak check runs.
+		t.Cleanup(a.Done)
+		checkGoroutines(t)
+		ctx, cancel := context.WithCancel(t.Context())
+		ch := AwaitAll(ctx, &a)
+		cancel()
+		select {
+		case <-ch:
+			t.Fatal("expected open channel while a is busy")
+		case <-time.After(time.Millisecond):
+		}
+	})
+}